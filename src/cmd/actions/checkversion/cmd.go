@@ -3,7 +3,9 @@ package checkversion
 import (
 	"denver/cmd"
 	"denver/pkg/notify"
+	"denver/pkg/output"
 	"denver/pkg/providers"
+	"denver/pkg/providers/virtualbox"
 	"denver/pkg/updater"
 	"fmt"
 	"log"
@@ -19,6 +21,24 @@ type CheckVersion struct {
 	notify     notify.Notify
 }
 
+// versionsData is the data of check-version in the machine outputs
+type versionsData struct {
+	Denver componentVersion `json:"denver" yaml:"denver"`
+	RBI    componentVersion `json:"rbi" yaml:"rbi"`
+}
+
+// componentVersion tells the version of a component, Latest is only known for denver itself
+type componentVersion struct {
+	Current  string `json:"current,omitempty" yaml:"current,omitempty"`
+	Latest   string `json:"latest,omitempty" yaml:"latest,omitempty"`
+	UpToDate bool   `json:"upToDate" yaml:"upToDate"`
+}
+
+// rbiManifester is implemented by the providers telling the version of the installed RBI
+type rbiManifester interface {
+	GetRBIManifest() (virtualbox.Manifest, error)
+}
+
 // NewCheckVersion returns a pointer to CheckVersion
 func NewCheckVersion(vmProvider *providers.VMProvider, updater updater.Updater, printer *log.Logger, notify notify.Notify) *CheckVersion {
 	return &CheckVersion{
@@ -32,10 +52,16 @@ func NewCheckVersion(vmProvider *providers.VMProvider, updater updater.Updater,
 // GetCommand returns a valid cmd command
 func (c *CheckVersion) GetCommand() cmd.DenverCommand {
 	return cmd.DenverCommand{
-		Name: "check-version",
-		Desc: "Checks if you are running latest Denver version",
+		Name:      "check-version",
+		Desc:      "Checks if you are running latest Denver version",
+		Preflight: true,
 		Exec: func() (err error) {
-			controllerUpToDate, err := c.checkForControllerUpdate()
+			data := versionsData{Denver: componentVersion{Current: cmd.Version}}
+			defer func() {
+				output.SetData(c.printer, data)
+			}()
+
+			controllerUpToDate, err := c.checkForControllerUpdate(&data.Denver)
 			if err != nil {
 				return
 			}
@@ -44,6 +70,12 @@ func (c *CheckVersion) GetCommand() cmd.DenverCommand {
 			if err != nil {
 				return
 			}
+			data.RBI.UpToDate = rbiUpToDate
+			if manifester, ok := (*c.vmProvider).(rbiManifester); ok {
+				if manifest, err := manifester.GetRBIManifest(); err == nil {
+					data.RBI.Current = manifest.Version
+				}
+			}
 
 			if controllerUpToDate && rbiUpToDate {
 				c.printer.Println(fmt.Sprintf("%s %s",
@@ -59,7 +91,7 @@ func (c *CheckVersion) GetCommand() cmd.DenverCommand {
 
 // CheckForUpdates checks whether the Controller or the Root Base Image has new updates
 func (c *CheckVersion) CheckForUpdates() (err error) {
-	if _, err = c.checkForControllerUpdate(); err != nil {
+	if _, err = c.checkForControllerUpdate(&componentVersion{}); err != nil {
 		return
 	}
 	if _, err = c.checkForRootBaseImageUpdate(); err != nil {
@@ -68,11 +100,13 @@ func (c *CheckVersion) CheckForUpdates() (err error) {
 	return
 }
 
-func (c *CheckVersion) checkForControllerUpdate() (isUpToDate bool, err error) {
-	_, isUpToDate, err = c.updater.CheckIsUpdated(cmd.Version)
+func (c *CheckVersion) checkForControllerUpdate(version *componentVersion) (isUpToDate bool, err error) {
+	manifest, isUpToDate, err := c.updater.CheckIsUpdated(cmd.Version)
 	if err != nil {
 		return
 	}
+	version.Latest = manifest.Release
+	version.UpToDate = isUpToDate
 	if isUpToDate {
 		return
 	}
@@ -95,6 +129,12 @@ func (c *CheckVersion) checkForRootBaseImageUpdate() (isUpToDate bool, err error
 		return
 	}
 
+	// The update recreates the VM, a suspended one would lose its saved state
+	if (*c.vmProvider).GetState().Saved {
+		log.Println("A new version for the Root Base Image is available, stop the instance to upgrade")
+		return
+	}
+
 	answer := c.notify.AskQuestion("A new version for the Root Base Image is available, do you want to upgrade ?")
 	if !answer {
 		return
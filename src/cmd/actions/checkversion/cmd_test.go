@@ -16,6 +16,10 @@ type testingVM struct {
 	providers.Testing
 }
 
+func (t *testingVM) GetState() *providers.State {
+	return providers.NewState()
+}
+
 func getVMProvider(provider interface{}) providers.VMProvider {
 	return provider.(providers.VMProvider)
 }
@@ -0,0 +1,117 @@
+package disk
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+// Disk action
+type Disk struct {
+	vmProvider *providers.VMProvider
+	printer    *log.Logger
+}
+
+// NewDisk returns a pointer to Disk
+func NewDisk(vmProvider *providers.VMProvider, printer *log.Logger) *Disk {
+	return &Disk{
+		vmProvider: vmProvider,
+		printer:    printer,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (d *Disk) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "disk",
+		Desc: "Manage the userdata disk of the instance",
+		SubCommands: []cmd.DenverCommand{
+			{
+				Name:     "grow <size>",
+				Desc:     "Grow the userdata disk to the given size (e.g. 64G, 70000M)",
+				Args:     cobra.ExactArgs(1),
+				ExecArgs: d.grow,
+			},
+		},
+	}
+}
+
+func (d *Disk) grow(args []string) (err error) {
+	size, err := ParseSize(args[0])
+	if err != nil {
+		return
+	}
+
+	state := (*d.vmProvider).GetState()
+	if state.Live {
+		return output.Errorf(output.CodeInvalidState, "VM is started, stop it first")
+	}
+
+	grower, ok := (*d.vmProvider).(providers.DiskGrower)
+	if !ok {
+		return output.Errorf(output.CodeUnsupported, "the VM provider does not support growing disks")
+	}
+
+	before, err := grower.UserDataSize()
+	if err != nil {
+		return
+	}
+	if size <= before {
+		return output.Errorf(output.CodeInvalidArgument, "the userdata disk is already %d MB, it can't be shrunk to %d MB", before, size)
+	}
+
+	if err = grower.GrowUserData(size); err != nil {
+		return
+	}
+
+	after, err := grower.UserDataSize()
+	if err != nil {
+		return
+	}
+
+	d.printer.Println(fmt.Sprintf("%s Userdata disk grown from %d MB to %d MB",
+		aurora.Bold(aurora.Green("[OK]")),
+		before,
+		after,
+	))
+	d.printer.Println(fmt.Sprintf("%s %s",
+		aurora.Bold(aurora.Yellow("[INFO]")),
+		"The guest filesystem will be grown on next start",
+	))
+
+	return
+}
+
+// ParseSize converts a size to megabytes, gigabytes being the default unit like userdatasize
+func ParseSize(size string) (int, error) {
+	units := map[string]int{
+		"M": 1,
+		"G": 1024,
+		"T": 1024 * 1024,
+	}
+
+	value := strings.ToUpper(strings.TrimSpace(size))
+	value = strings.TrimSuffix(value, "B")
+
+	multiplier := units["G"]
+	if len(value) > 0 {
+		if unit, ok := units[value[len(value)-1:]]; ok {
+			multiplier = unit
+			value = value[:len(value)-1]
+		}
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, output.Errorf(output.CodeInvalidArgument, "invalid size %s", size)
+	}
+
+	return number * multiplier, nil
+}
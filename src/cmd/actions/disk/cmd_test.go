@@ -0,0 +1,96 @@
+package disk
+
+import (
+	"bytes"
+	"denver/pkg/providers"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	size  int
+	state *providers.State
+}
+
+func (t *testingVM) GetState() (state *providers.State) { return t.state }
+func (t *testingVM) UserDataSize() (int, error)         { return t.size, nil }
+func (t *testingVM) GrowUserData(size int) error {
+	t.size = size
+	return nil
+}
+func (t *testingVM) GuestGrowPending() (bool, error) { return false, nil }
+func (t *testingVM) SetGuestGrowDone() error         { return nil }
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func TestParseSize(t *testing.T) {
+	assert := assert.New(t)
+
+	testcases := []struct {
+		size     string
+		expected int
+	}{
+		{"64", 65536},
+		{"64G", 65536},
+		{"64gb", 65536},
+		{"70000M", 70000},
+		{"1T", 1048576},
+	}
+
+	for _, testcase := range testcases {
+		size, err := ParseSize(testcase.size)
+		assert.NoError(err)
+		assert.Equal(testcase.expected, size, testcase.size)
+	}
+
+	for _, size := range []string{"", "G", "-1G", "big"} {
+		_, err := ParseSize(size)
+		assert.Error(err, size)
+	}
+}
+
+func TestGrowRefusesToShrink(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		size:  32768,
+		state: &providers.State{},
+	})
+
+	cmd := NewDisk(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"16G"})
+	assert.EqualError(err, "the userdata disk is already 32768 MB, it can't be shrunk to 16384 MB")
+}
+
+func TestGrowFailsIfVMIsOn(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		state: &providers.State{Live: true},
+	})
+
+	cmd := NewDisk(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"64G"})
+	assert.EqualError(err, "VM is started, stop it first")
+}
+
+func TestGrowReportsSizes(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		size:  32768,
+		state: &providers.State{},
+	})
+	var out bytes.Buffer
+
+	cmd := NewDisk(&vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"64G"})
+	assert.NoError(err)
+	assert.Contains(out.String(), "Userdata disk grown from 32768 MB to 65536 MB")
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"denver/cmd"
+	"denver/pkg/archive"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"denver/pkg/util/compressor"
+	"denver/structs"
+	"fmt"
+	"log"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+// Export action
+type Export struct {
+	workingDirectory string
+	config           *structs.Denver
+	instanceName     *string
+	vmProvider       *providers.VMProvider
+	printer          *log.Logger
+}
+
+// Import action
+type Import struct {
+	Export
+}
+
+// NewExport returns a pointer to Export
+func NewExport(workingDirectory string, config *structs.Denver, instanceName *string, vmProvider *providers.VMProvider, printer *log.Logger) *Export {
+	return &Export{
+		workingDirectory: workingDirectory,
+		config:           config,
+		instanceName:     instanceName,
+		vmProvider:       vmProvider,
+		printer:          printer,
+	}
+}
+
+// NewImport returns a pointer to Import
+func NewImport(workingDirectory string, config *structs.Denver, instanceName *string, vmProvider *providers.VMProvider, printer *log.Logger) *Import {
+	return &Import{
+		Export: *NewExport(workingDirectory, config, instanceName, vmProvider, printer),
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (e *Export) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "export <file.tar.bz2>",
+		Desc: "Export the instance to a portable archive",
+		Args: cobra.ExactArgs(1),
+		ExecArgs: func(args []string) (err error) {
+			state := (*e.vmProvider).GetState()
+			if state.Live {
+				return output.Errorf(output.CodeInvalidState, "VM is started, stop it first")
+			}
+
+			a, err := e.getArchive()
+			if err != nil {
+				return
+			}
+
+			if err = a.Export(args[0]); err != nil {
+				return
+			}
+
+			e.printer.Println(fmt.Sprintf("%s Instance has been exported to %s",
+				aurora.Bold(aurora.Green("[OK]")),
+				args[0],
+			))
+
+			return
+		},
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (i *Import) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "import <file.tar.bz2>",
+		Desc: "Import an exported instance and initialize it",
+		Args: cobra.ExactArgs(1),
+		ExecArgs: func(args []string) (err error) {
+			a, err := i.getArchive()
+			if err != nil {
+				return
+			}
+
+			manifest, err := a.Import(args[0])
+			if err != nil {
+				return
+			}
+
+			if err = (*i.vmProvider).Init(); err != nil {
+				return
+			}
+
+			rbi, err := (*i.vmProvider).(providers.Exportable).GetRBIManifest()
+			if err != nil {
+				return
+			}
+			if rbi.Version != manifest.RBI.Version {
+				i.printer.Println(fmt.Sprintf("%s Archive was exported with RBI %s, the instance now runs RBI %s",
+					aurora.Bold(aurora.Yellow("[INFO]")),
+					manifest.RBI.Version,
+					rbi.Version,
+				))
+			}
+
+			i.printer.Println(fmt.Sprintf("%s Instance %s has been imported",
+				aurora.Bold(aurora.Green("[OK]")),
+				manifest.Instance,
+			))
+
+			return
+		},
+	}
+}
+
+func (e *Export) getArchive() (*archive.Archive, error) {
+	exportable, ok := (*e.vmProvider).(providers.Exportable)
+	if !ok {
+		return nil, output.Errorf(output.CodeUnsupported, "the VM provider does not support exports")
+	}
+
+	instance, err := e.config.GetInstance(*e.instanceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return archive.NewArchive(
+		e.workingDirectory,
+		e.config,
+		instance,
+		exportable,
+		compressor.NewMultiCompressor(),
+	), nil
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"denver/pkg/providers"
+	"denver/structs"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func TestExportIsRefusedWhileTheVMIsRunning(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Live: true}))
+	instanceName := ""
+
+	cmd := NewExport("", &structs.Denver{}, &instanceName, &vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().ExecArgs([]string{"denver.tar.bz2"})
+	assert.EqualError(err, "VM is started, stop it first")
+}
+
+func TestExportFailsIfProviderDoesNotSupportIt(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{}))
+	instanceName := ""
+
+	cmd := NewExport("", &structs.Denver{}, &instanceName, &vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().ExecArgs([]string{"denver.tar.bz2"})
+	assert.EqualError(err, "the VM provider does not support exports")
+}
+
+func TestImportFailsIfProviderDoesNotSupportIt(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{}))
+	instanceName := ""
+
+	cmd := NewImport("", &structs.Denver{}, &instanceName, &vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().ExecArgs([]string{"denver.tar.bz2"})
+	assert.EqualError(err, "the VM provider does not support exports")
+}
@@ -0,0 +1,117 @@
+package info
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// Info action
+type Info struct {
+	vmProvider *providers.VMProvider
+	printer    *log.Logger
+	format     string
+}
+
+// NewInfo returns a pointer to Info
+func NewInfo(vmProvider *providers.VMProvider, printer *log.Logger) *Info {
+	return &Info{
+		vmProvider: vmProvider,
+		printer:    printer,
+		format:     "table",
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (i *Info) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "info",
+		Desc: "Describe the virtual machine of the instance",
+		Exec: i.info,
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringVar(&i.format, "format", "table", "Output format (table|json|yaml)")
+		},
+	}
+}
+
+func (i *Info) info() (err error) {
+	informer, ok := (*i.vmProvider).(providers.Informer)
+	if !ok {
+		return output.Errorf(output.CodeUnsupported, "the VM provider does not support describing the VM")
+	}
+
+	info, err := informer.Info()
+	if err != nil {
+		return
+	}
+
+	// --output takes over --format, which predates it
+	if output.SetData(i.printer, info) {
+		return
+	}
+
+	switch i.format {
+	case "json":
+		body, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		i.printer.Println(string(body))
+	case "yaml":
+		body, err := yaml.Marshal(info)
+		if err != nil {
+			return err
+		}
+		i.printer.Print(string(body))
+	case "table":
+		return i.table(info)
+	default:
+		return output.Errorf(output.CodeInvalidArgument, "unknown format %s", i.format)
+	}
+
+	return
+}
+
+func (i *Info) table(info *providers.VMInfo) error {
+	w := tabwriter.NewWriter(i.printer.Writer(), 0, 0, 2, ' ', 0)
+
+	state := info.State
+	if info.Uptime != "" {
+		state = fmt.Sprintf("%s (up %s)", state, info.Uptime)
+	}
+	snapshots := strings.Join(info.Snapshots, ", ")
+	if snapshots == "" {
+		snapshots = "-"
+	}
+
+	fmt.Fprintf(w, "NAME\t%s\n", info.Name)
+	fmt.Fprintf(w, "UUID\t%s\n", info.UUID)
+	fmt.Fprintf(w, "STATE\t%s\n", state)
+	fmt.Fprintf(w, "CPUS\t%d\n", info.CPUs)
+	fmt.Fprintf(w, "MEMORY\t%d MB\n", info.Memory)
+	fmt.Fprintf(w, "SNAPSHOTS\t%s\n", snapshots)
+
+	fmt.Fprintln(w, "\nNIC\tTYPE\tNETWORK\tMAC")
+	for _, nic := range info.NICs {
+		network := nic.Network
+		if network == "" {
+			network = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", nic.Index, nic.Type, network, nic.MAC)
+	}
+
+	fmt.Fprintln(w, "\nCONTROLLER\tPORT\tMEDIUM")
+	for _, attachment := range info.Storage {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", attachment.Controller, attachment.Port, attachment.Medium)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,114 @@
+package info
+
+import (
+	"bytes"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"encoding/json"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	info *providers.VMInfo
+}
+
+func (t *testingVM) Info() (*providers.VMInfo, error) { return t.info, nil }
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func getInfo() *providers.VMInfo {
+	return &providers.VMInfo{
+		Name:   "denver",
+		UUID:   "6e3b1c2a",
+		State:  "poweroff",
+		CPUs:   2,
+		Memory: 2048,
+		NICs: []providers.NIC{
+			{Index: 1, Type: "nat", MAC: "080027A1B2C3"},
+		},
+		Storage:   []providers.StorageAttachment{},
+		Snapshots: []string{},
+	}
+}
+
+func TestCommandFailsIfProviderDoesNotSupportInfo(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&providers.Testing{})
+
+	cmd := NewInfo(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "the VM provider does not support describing the VM")
+}
+
+func TestInfoPrintsJSON(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{info: getInfo()})
+	var out bytes.Buffer
+
+	cmd := NewInfo(&vm, log.New(&out, "", 0))
+	cmd.format = "json"
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"name": "denver", "uuid": "6e3b1c2a", "state": "poweroff", "stateSince": "0001-01-01T00:00:00Z",
+		"cpus": 2, "memory": 2048,
+		"nics": [{"index": 1, "type": "nat", "mac": "080027A1B2C3"}],
+		"storage": [], "snapshots": []
+	}`, out.String())
+}
+
+func TestInfoPrintsYAML(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{info: getInfo()})
+	var out bytes.Buffer
+
+	cmd := NewInfo(&vm, log.New(&out, "", 0))
+	cmd.format = "yaml"
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "name: denver\n")
+	assert.Contains(out.String(), "- index: 1\n  type: nat\n")
+}
+
+func TestInfoPrintsATable(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{info: getInfo()})
+	var out bytes.Buffer
+
+	cmd := NewInfo(&vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "STATE      poweroff\n")
+	assert.Contains(out.String(), "1    nat   -        080027A1B2C3\n")
+}
+
+func TestInfoIsTheDataOfTheMachineOutput(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{info: getInfo()})
+	var out bytes.Buffer
+	printer := output.NewPrinter(output.JSON, &out)
+
+	cmd := NewInfo(&vm, log.New(printer, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Empty(out.String())
+
+	assert.NoError(printer.Flush("info", "v1", err))
+	var result struct {
+		Data providers.VMInfo
+	}
+	assert.NoError(json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(*getInfo(), result.Data)
+}
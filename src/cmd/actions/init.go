@@ -3,11 +3,13 @@ package actions
 import (
 	"denver/cmd"
 	"denver/cmd/actions/checkversion"
+	"denver/pkg/output"
 	"denver/pkg/providers"
 	"fmt"
 	"log"
 
 	"github.com/logrusorgru/aurora"
+	"github.com/spf13/pflag"
 )
 
 // Init action
@@ -15,6 +17,7 @@ type Init struct {
 	vmProvider   *providers.VMProvider
 	printer      *log.Logger
 	checkVersion *checkversion.CheckVersion
+	resume       bool
 }
 
 // NewInit returns a pointer to Init
@@ -29,15 +32,26 @@ func NewInit(vmProvider *providers.VMProvider, printer *log.Logger, checkVersion
 // GetCommand returns a valid cmd command
 func (i *Init) GetCommand() cmd.DenverCommand {
 	return cmd.DenverCommand{
-		Name: "init",
-		Desc: "Init the instance",
+		Name:      "init",
+		Desc:      "Init the instance",
+		Preflight: true,
 		Exec: func() (err error) {
-			if err = (*i.checkVersion).CheckForUpdates(); err != nil {
-				return
-			}
+			if i.resume {
+				resumer, ok := (*i.vmProvider).(providers.InitResumer)
+				if !ok {
+					return output.Errorf(output.CodeUnsupported, "the VM provider does not support resuming init")
+				}
+				if err = resumer.ResumeInit(); err != nil {
+					return
+				}
+			} else {
+				if err = (*i.checkVersion).CheckForUpdates(); err != nil {
+					return
+				}
 
-			if err = (*i.vmProvider).Init(); err != nil {
-				return
+				if err = (*i.vmProvider).Init(); err != nil {
+					return
+				}
 			}
 
 			i.printer.Println(fmt.Sprintf("%s %s",
@@ -47,5 +61,8 @@ func (i *Init) GetCommand() cmd.DenverCommand {
 
 			return
 		},
+		Flags: func(flags *pflag.FlagSet) {
+			flags.BoolVar(&i.resume, "resume", false, "Continue an init which has been interrupted")
+		},
 	}
 }
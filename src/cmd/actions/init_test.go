@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"bytes"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitInstallsTheVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewInit(&vm, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"CheckIsUpdated", "Init"}, fake.Calls())
+	assert.Contains(out.String(), "VM has been installed")
+}
+
+func TestInitFailsIfTheProviderFails(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{}).
+		Fail("Init", fmt.Errorf("this is fine"))
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewInit(&vm, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "this is fine")
+	assert.Empty(out.String())
+}
+
+func TestInitCanBeResumed(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewInit(&vm, log.New(&out, "", 0), getCheckVersion(&vm))
+	cmd.resume = true
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"ResumeInit"}, fake.Calls())
+	assert.Contains(out.String(), "VM has been installed")
+}
+
+func TestInitResumeFailsIfProviderDoesNotSupportIt(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{state: &providers.State{}})
+
+	cmd := NewInit(&vm, (*log.Logger)(nil), nil)
+	cmd.resume = true
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "the VM provider does not support resuming init")
+}
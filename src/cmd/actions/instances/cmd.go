@@ -0,0 +1,106 @@
+package instances
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/structs"
+	"fmt"
+	"log"
+	"text/tabwriter"
+)
+
+// Instances action
+type Instances struct {
+	config   *structs.Denver
+	selected *string
+	printer  *log.Logger
+}
+
+// instanceData describes an instance in the machine outputs
+type instanceData struct {
+	Name     string `json:"name" yaml:"name"`
+	Provider string `json:"provider" yaml:"provider"`
+	Vcpu     int    `json:"vcpu" yaml:"vcpu"`
+	Vmem     int    `json:"vmem" yaml:"vmem"`
+	Localip  string `json:"localip" yaml:"localip"`
+	Store    string `json:"store" yaml:"store"`
+	Selected bool   `json:"selected" yaml:"selected"`
+}
+
+// NewInstances returns a pointer to Instances
+func NewInstances(config *structs.Denver, selected *string, printer *log.Logger) *Instances {
+	return &Instances{
+		config:   config,
+		selected: selected,
+		printer:  printer,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (i *Instances) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name:       "instances",
+		Desc:       "Manage the configured instances",
+		ConfigOnly: true,
+		SubCommands: []cmd.DenverCommand{
+			{
+				Name: "list",
+				Desc: "List the configured instances",
+				Exec: i.list,
+			},
+		},
+	}
+}
+
+func (i *Instances) list() (err error) {
+	instances, err := i.config.GetInstances()
+	if err != nil {
+		return
+	}
+
+	// The selected instance is only marked, an ambiguous selection is not an error here
+	var selected string
+	if instance, err := i.config.GetInstance(*i.selected); err == nil {
+		selected = instance.Name
+	}
+
+	data := []instanceData{}
+	for _, name := range structs.InstanceNames(instances) {
+		instance := instances[name]
+		data = append(data, instanceData{
+			Name:     instance.Name,
+			Provider: instance.Provider,
+			Vcpu:     instance.Vcpu,
+			Vmem:     instance.Vmem,
+			Localip:  instance.Localip,
+			Store:    instance.Store,
+			Selected: name == selected,
+		})
+	}
+	if output.SetData(i.printer, data) {
+		return
+	}
+
+	w := tabwriter.NewWriter(i.printer.Writer(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tPROVIDER\tVCPU\tVMEM\tLOCALIP\tSTORE")
+	for _, name := range structs.InstanceNames(instances) {
+		instance := instances[name]
+
+		marker := ""
+		if name == selected {
+			marker = "*"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			marker,
+			instance.Name,
+			instance.Provider,
+			instance.Vcpu,
+			instance.Vmem,
+			instance.Localip,
+			instance.Store,
+		)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,61 @@
+package instances
+
+import (
+	"bytes"
+	"denver/structs"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getConfig() *structs.Denver {
+	config := structs.NewDenverConfig()
+	config.Instance = &structs.InstanceConf{Name: "denver", Provider: "local-vb", Vcpu: 2, Vmem: 2048, Localip: "10.10.10.10"}
+	config.Instances = map[string]*structs.InstanceConf{
+		"legacy-php": {Provider: "local-vb", Vcpu: 1, Vmem: 1024, Localip: "10.10.20.10"},
+	}
+
+	return config
+}
+
+func TestListMarksTheDefaultInstance(t *testing.T) {
+	assert := assert.New(t)
+	var out bytes.Buffer
+	selected := ""
+
+	cmd := NewInstances(getConfig(), &selected, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[0].Exec()
+	assert.NoError(err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(lines, 3)
+	assert.Regexp(`^\*\s+denver\s+local-vb\s+2\s+2048\s+10\.10\.10\.10\s+store$`, lines[1])
+	assert.Regexp(`^\s+legacy-php\s+local-vb\s+1\s+1024\s+10\.10\.20\.10\s+store/legacy-php$`, lines[2])
+}
+
+func TestListMarksTheSelectedInstance(t *testing.T) {
+	assert := assert.New(t)
+	var out bytes.Buffer
+	selected := "legacy-php"
+
+	cmd := NewInstances(getConfig(), &selected, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[0].Exec()
+	assert.NoError(err)
+	assert.Regexp(`\*\s+legacy-php`, out.String())
+}
+
+func TestListFailsWithDuplicatedInstances(t *testing.T) {
+	assert := assert.New(t)
+	config := getConfig()
+	config.Instances["denver"] = &structs.InstanceConf{}
+	selected := ""
+
+	cmd := NewInstances(config, &selected, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].Exec()
+	assert.EqualError(err, "instance denver is declared twice")
+}
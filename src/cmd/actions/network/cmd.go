@@ -0,0 +1,69 @@
+package network
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// Network action
+type Network struct {
+	vmProvider *providers.VMProvider
+	printer    *log.Logger
+}
+
+// NewNetwork returns a pointer to Network
+func NewNetwork(vmProvider *providers.VMProvider, printer *log.Logger) *Network {
+	return &Network{
+		vmProvider: vmProvider,
+		printer:    printer,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (n *Network) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "network",
+		Desc: "Manage the host networks of the instances",
+		SubCommands: []cmd.DenverCommand{
+			{
+				Name: "prune",
+				Desc: "Remove the host-only interfaces no VM uses anymore",
+				Exec: n.prune,
+			},
+		},
+	}
+}
+
+func (n *Network) prune() (err error) {
+	pruner, ok := (*n.vmProvider).(providers.NetworkPruner)
+	if !ok {
+		return output.Errorf(output.CodeUnsupported, "the VM provider does not support pruning networks")
+	}
+
+	removed, err := pruner.PruneNetworks()
+	if err != nil {
+		return
+	}
+
+	if len(removed) == 0 {
+		n.printer.Println(fmt.Sprintf("%s %s",
+			aurora.Bold(aurora.Yellow("[SKIP]")),
+			"No orphaned host-only interface",
+		))
+		return
+	}
+
+	for _, name := range removed {
+		n.printer.Println(fmt.Sprintf("%s Host-only interface %s has been removed",
+			aurora.Bold(aurora.Green("[OK]")),
+			name,
+		))
+	}
+
+	return
+}
@@ -0,0 +1,45 @@
+package network
+
+import (
+	"bytes"
+	"denver/pkg/providers"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	removed []string
+}
+
+func (t *testingVM) PruneNetworks() ([]string, error) { return t.removed, nil }
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func TestCommandFailsIfProviderDoesNotSupportPruning(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&providers.Testing{})
+
+	cmd := NewNetwork(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].Exec()
+	assert.EqualError(err, "the VM provider does not support pruning networks")
+}
+
+func TestPruneReportsRemovedInterfaces(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{removed: []string{"vboxnet2", "vboxnet3"}})
+	var out bytes.Buffer
+
+	cmd := NewNetwork(&vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[0].Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "Host-only interface vboxnet2 has been removed")
+	assert.Contains(out.String(), "Host-only interface vboxnet3 has been removed")
+}
@@ -0,0 +1,167 @@
+package port
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"text/tabwriter"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+// Port action
+type Port struct {
+	vmProvider *providers.VMProvider
+	save       func(ports []string) error
+	printer    *log.Logger
+}
+
+// NewPort returns a pointer to Port, save persists the forwards in the instance configuration
+func NewPort(vmProvider *providers.VMProvider, save func(ports []string) error, printer *log.Logger) *Port {
+	return &Port{
+		vmProvider: vmProvider,
+		save:       save,
+		printer:    printer,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (p *Port) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "port",
+		Desc: "Manage the ports forwarded from localhost to the instance",
+		SubCommands: []cmd.DenverCommand{
+			{
+				Name:     "add <host>:<guest>",
+				Desc:     "Forward a port of localhost to the instance",
+				Args:     cobra.ExactArgs(1),
+				ExecArgs: p.add,
+			},
+			{
+				Name: "list",
+				Desc: "List the forwarded ports",
+				Exec: p.list,
+			},
+			{
+				Name:     "remove <host>:<guest>",
+				Desc:     "Stop forwarding a port",
+				Args:     cobra.ExactArgs(1),
+				ExecArgs: p.remove,
+			},
+		},
+	}
+}
+
+func (p *Port) add(args []string) (err error) {
+	forwarder, forward, err := p.parse(args[0])
+	if err != nil {
+		return
+	}
+
+	if err = forwarder.AddPortForward(forward); err != nil {
+		return
+	}
+
+	if err = p.persist(forwarder); err != nil {
+		// The configuration restores the forwards when the VM is recreated, they must not diverge
+		if undoErr := forwarder.RemovePortForward(forward); undoErr != nil {
+			return fmt.Errorf("%s\nthe forward could not be removed either: %s", err, undoErr)
+		}
+		return
+	}
+
+	p.printer.Println(fmt.Sprintf("%s localhost:%d is forwarded to the port %d of the instance",
+		aurora.Bold(aurora.Green("[OK]")),
+		forward.Host,
+		forward.Guest,
+	))
+
+	return
+}
+
+func (p *Port) list() (err error) {
+	forwarder, err := p.getPortForwarder()
+	if err != nil {
+		return
+	}
+
+	forwards, err := forwarder.ListPortForwards()
+	if err != nil {
+		return
+	}
+	if forwards == nil {
+		forwards = []providers.PortForward{}
+	}
+	if output.SetData(p.printer, forwards) {
+		return
+	}
+
+	w := tabwriter.NewWriter(p.printer.Writer(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tGUEST")
+	for _, forward := range forwards {
+		fmt.Fprintf(w, "%d\t%d\n", forward.Host, forward.Guest)
+	}
+
+	return w.Flush()
+}
+
+func (p *Port) remove(args []string) (err error) {
+	forwarder, forward, err := p.parse(args[0])
+	if err != nil {
+		return
+	}
+
+	if err = forwarder.RemovePortForward(forward); err != nil {
+		return
+	}
+
+	if err = p.persist(forwarder); err != nil {
+		if undoErr := forwarder.AddPortForward(forward); undoErr != nil {
+			return fmt.Errorf("%s\nthe forward could not be restored either: %s", err, undoErr)
+		}
+		return
+	}
+
+	p.printer.Println(fmt.Sprintf("%s Port forward %s has been removed",
+		aurora.Bold(aurora.Green("[OK]")),
+		forward,
+	))
+
+	return
+}
+
+func (p *Port) parse(definition string) (forwarder providers.PortForwarder, forward providers.PortForward, err error) {
+	if forwarder, err = p.getPortForwarder(); err != nil {
+		return
+	}
+
+	forward, err = providers.ParsePortForward(definition)
+	return
+}
+
+// persist saves the forwards of the VM so that they are restored when the VM is recreated
+func (p *Port) persist(forwarder providers.PortForwarder) (err error) {
+	forwards, err := forwarder.ListPortForwards()
+	if err != nil {
+		return
+	}
+
+	ports := []string{}
+	for _, forward := range forwards {
+		ports = append(ports, forward.String())
+	}
+
+	return p.save(ports)
+}
+
+func (p *Port) getPortForwarder() (providers.PortForwarder, error) {
+	forwarder, ok := (*p.vmProvider).(providers.PortForwarder)
+	if !ok {
+		return nil, output.Errorf(output.CodeUnsupported, "the VM provider does not support port forwarding")
+	}
+
+	return forwarder, nil
+}
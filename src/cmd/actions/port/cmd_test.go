@@ -0,0 +1,126 @@
+package port
+
+import (
+	"bytes"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	forwards []providers.PortForward
+}
+
+func (t *testingVM) AddPortForward(forward providers.PortForward) error {
+	t.forwards = append(t.forwards, forward)
+	return nil
+}
+func (t *testingVM) RemovePortForward(forward providers.PortForward) error {
+	var forwards []providers.PortForward
+	for _, f := range t.forwards {
+		if f != forward {
+			forwards = append(forwards, f)
+		}
+	}
+	t.forwards = forwards
+	return nil
+}
+func (t *testingVM) ListPortForwards() ([]providers.PortForward, error) { return t.forwards, nil }
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func TestCommandFailsIfProviderDoesNotSupportPortForwarding(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&providers.Testing{})
+
+	cmd := NewPort(&vm, nil, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"8080:80"})
+	assert.EqualError(err, "the VM provider does not support port forwarding")
+}
+
+func TestAddPersistsTheForwards(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		forwards: []providers.PortForward{{Host: 443, Guest: 8443}},
+	})
+	var saved []string
+	var out bytes.Buffer
+
+	cmd := NewPort(&vm, func(ports []string) error {
+		saved = ports
+		return nil
+	}, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"8080:80"})
+	assert.NoError(err)
+	assert.Equal([]string{"443:8443", "8080:80"}, saved)
+}
+
+func TestRemovePersistsTheForwards(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		forwards: []providers.PortForward{{Host: 8080, Guest: 80}},
+	})
+	var saved []string
+	var out bytes.Buffer
+
+	cmd := NewPort(&vm, func(ports []string) error {
+		saved = ports
+		return nil
+	}, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[2].ExecArgs([]string{"8080:80"})
+	assert.NoError(err)
+	assert.Equal([]string{}, saved)
+}
+
+func TestForwardIsUndoneIfItCannotBePersisted(t *testing.T) {
+	assert := assert.New(t)
+	forwards := []providers.PortForward{{Host: 8080, Guest: 80}}
+	vm := &testingVM{forwards: forwards}
+	provider := getVMProvider(vm)
+
+	cmd := NewPort(&provider, func(ports []string) error {
+		return fmt.Errorf("permission denied")
+	}, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"8443:443"})
+	assert.EqualError(err, "permission denied")
+	assert.Equal(forwards, vm.forwards)
+
+	err = cmd.GetCommand().SubCommands[2].ExecArgs([]string{"8080:80"})
+	assert.EqualError(err, "permission denied")
+	assert.Equal(forwards, vm.forwards)
+}
+
+func TestInvalidDefinitionIsRefused(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{})
+
+	cmd := NewPort(&vm, nil, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"localhost:80"})
+	assert.EqualError(err, "invalid port forward localhost:80, localhost is not a valid port")
+}
+
+func TestListPrintsATable(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		forwards: []providers.PortForward{{Host: 8080, Guest: 80}},
+	})
+	var out bytes.Buffer
+
+	cmd := NewPort(&vm, nil, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[1].Exec()
+	assert.NoError(err)
+	assert.Equal("HOST  GUEST\n8080  80\n", out.String())
+}
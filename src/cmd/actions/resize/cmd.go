@@ -0,0 +1,68 @@
+package resize
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// Resize action
+type Resize struct {
+	vmProvider *providers.VMProvider
+	printer    *log.Logger
+}
+
+// NewResize returns a pointer to Resize
+func NewResize(vmProvider *providers.VMProvider, printer *log.Logger) *Resize {
+	return &Resize{
+		vmProvider: vmProvider,
+		printer:    printer,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (r *Resize) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "resize",
+		Desc: "Apply the configured CPUs and memory to the instance",
+		Exec: func() error {
+			state := (*r.vmProvider).GetState()
+			if state.Live {
+				return output.Errorf(output.CodeInvalidState, "VM is started, stop it first")
+			}
+
+			resizer, ok := (*r.vmProvider).(providers.Resizer)
+			if !ok {
+				return output.Errorf(output.CodeUnsupported, "the VM provider does not support resizing")
+			}
+
+			changes, err := resizer.Resize()
+			if err != nil {
+				return err
+			}
+
+			if len(changes) == 0 {
+				r.printer.Println(fmt.Sprintf("%s %s",
+					aurora.Bold(aurora.Yellow("[SKIP]")),
+					"VM resources already match the configuration",
+				))
+				return nil
+			}
+
+			for _, change := range changes {
+				r.printer.Println(fmt.Sprintf("%s %s changed from %s to %s",
+					aurora.Bold(aurora.Green("[OK]")),
+					change.Name,
+					change.From,
+					change.To,
+				))
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package resize
+
+import (
+	"bytes"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	changes   []providers.ResourceChange
+	resizeErr error
+	state     *providers.State
+}
+
+func (t *testingVM) GetState() (state *providers.State) { return t.state }
+func (t *testingVM) Resize() ([]providers.ResourceChange, error) {
+	return t.changes, t.resizeErr
+}
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func TestCommandFailsIfVMIsOn(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		state: &providers.State{Live: true},
+	})
+
+	cmd := NewResize(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "VM is started, stop it first")
+}
+
+func TestCommandFailsIfResizeFails(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		resizeErr: fmt.Errorf("this is fine"),
+		state:     &providers.State{},
+	})
+
+	cmd := NewResize(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "this is fine")
+}
+
+func TestCommandReportsChanges(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		changes: []providers.ResourceChange{
+			{Name: "vcpu", From: "2", To: "4"},
+			{Name: "vmem", From: "2048", To: "4096"},
+		},
+		state: &providers.State{},
+	})
+	var out bytes.Buffer
+
+	cmd := NewResize(&vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "vcpu changed from 2 to 4")
+	assert.Contains(out.String(), "vmem changed from 2048 to 4096")
+}
@@ -0,0 +1,36 @@
+package actions
+
+import (
+	"denver/cmd"
+)
+
+// Restart action
+type Restart struct {
+	stop  *Stop
+	start *Start
+}
+
+// NewRestart returns a pointer to Restart
+func NewRestart(stop *Stop, start *Start) *Restart {
+	return &Restart{
+		stop:  stop,
+		start: start,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (r *Restart) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name:      "restart",
+		Desc:      "Restart the instance",
+		Preflight: true,
+		Exec: func() (err error) {
+			if err = r.stop.stop(); err != nil {
+				return
+			}
+
+			return r.start.start()
+		},
+		Flags: r.stop.flags,
+	}
+}
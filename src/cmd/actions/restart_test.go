@@ -0,0 +1,53 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartStopsThenStartsTheVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true, OsReady: true, AllSystemsReady: true}).
+		Script("Stop", providers.ShutdownTransitions(100*time.Millisecond)...).
+		Script("Start", providers.BootTransitions(100*time.Millisecond)...)
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+	printer := log.New(&out, "", 0)
+
+	cmd := NewRestart(
+		NewStop(context.Background(), &vm, nil, printer),
+		NewStart(context.Background(), &vm, nil, printer, getCheckVersion(&vm)),
+	)
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"Stop", "CheckIsUpdated", "Start"}, fake.Calls())
+	assert.True(vm.GetState().AllSystemsReady)
+	assert.Contains(out.String(), "VM has been stopped")
+	assert.Contains(out.String(), "VM has been started")
+}
+
+func TestRestartDoesNotStartIfTheStopFails(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true}).
+		Fail("Stop", fmt.Errorf("this is fine"))
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+	printer := log.New(&out, "", 0)
+
+	cmd := NewRestart(
+		NewStop(context.Background(), &vm, nil, printer),
+		NewStart(context.Background(), &vm, nil, printer, getCheckVersion(&vm)),
+	)
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "this is fine")
+	assert.Equal([]string{"Stop"}, fake.Calls())
+}
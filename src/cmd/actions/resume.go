@@ -0,0 +1,39 @@
+package actions
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+)
+
+// Resume action
+type Resume struct {
+	vmProvider *providers.VMProvider
+	start      *Start
+}
+
+// NewResume returns a pointer to Resume
+func NewResume(vmProvider *providers.VMProvider, start *Start) *Resume {
+	return &Resume{
+		vmProvider: vmProvider,
+		start:      start,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (r *Resume) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name:      "resume",
+		Desc:      "Resume the suspended instance",
+		Preflight: true,
+		Exec: func() (err error) {
+			state := (*r.vmProvider).GetState()
+			if !state.Saved {
+				return output.Errorf(output.CodeInvalidState, "VM is not suspended")
+			}
+
+			// Start resumes a saved VM
+			return r.start.start()
+		},
+	}
+}
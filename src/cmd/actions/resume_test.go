@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"denver/pkg/providers"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getResume(vm *providers.VMProvider, out *bytes.Buffer) *Resume {
+	start := NewStart(context.Background(), vm, nil, log.New(out, "", 0), getCheckVersion(vm))
+	return NewResume(vm, start)
+}
+
+func TestResumeStartsASavedVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Saved: true}).
+		SetUpToDate(false).
+		Script("Start", providers.BootTransitions(0)...)
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	err := getResume(&vm, &out).GetCommand().Exec()
+	assert.NoError(err)
+	assert.False(vm.GetState().Saved)
+	assert.True(vm.GetState().AllSystemsReady)
+	// The RBI of a saved VM is kept
+	assert.Equal([]string{"CheckIsUpdated", "Start"}, fake.Calls())
+	assert.Contains(out.String(), "VM has been started")
+}
+
+func TestResumeFailsIfVMIsNotSuspended(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	err := getResume(&vm, &out).GetCommand().Exec()
+	assert.EqualError(err, "VM is not suspended")
+	assert.Empty(fake.Calls())
+	assert.Empty(out.String())
+}
+
+func TestResumeSynchronisesTheClockOnceStarted(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Saved: true}).
+		Script("Start", providers.BootTransitions(0)...)
+	// As registered by root, the guest clock is only synchronised after a resume
+	synced := false
+	fake.AddPostStartAction(func() error {
+		synced = fake.Resumed()
+		return nil
+	})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	err := getResume(&vm, &out).GetCommand().Exec()
+	assert.NoError(err)
+	assert.True(synced)
+}
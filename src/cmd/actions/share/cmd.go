@@ -0,0 +1,118 @@
+package share
+
+import (
+	"denver/cmd"
+	"denver/pkg/mount"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// Mount action
+type Mount struct {
+	mounter    *mount.Mounter
+	vmProvider *providers.VMProvider
+	printer    *log.Logger
+}
+
+// Umount action
+type Umount struct {
+	Mount
+}
+
+// NewMount returns a pointer to Mount
+func NewMount(mounter *mount.Mounter, vmProvider *providers.VMProvider, printer *log.Logger) *Mount {
+	return &Mount{
+		mounter:    mounter,
+		vmProvider: vmProvider,
+		printer:    printer,
+	}
+}
+
+// NewUmount returns a pointer to Umount
+func NewUmount(mounter *mount.Mounter, vmProvider *providers.VMProvider, printer *log.Logger) *Umount {
+	return &Umount{
+		Mount: *NewMount(mounter, vmProvider, printer),
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (m *Mount) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "mount",
+		Desc: "Mount the Projects folder of the instance on the host",
+		Exec: func() (err error) {
+			mounter := *m.mounter
+			isMounted, err := mounter.IsMounted()
+			if err != nil {
+				return
+			}
+
+			state := (*m.vmProvider).GetState()
+			if !state.Live && isMounted {
+				return output.Errorf(output.CodeInvalidState, "%s is a stale mount, the VM is not running, use umount first", mounter.Mountpoint())
+			}
+			if !state.AllSystemsReady {
+				return output.Errorf(output.CodeNotReady, "VM not ready")
+			}
+
+			if isMounted {
+				m.printer.Println(fmt.Sprintf("%s Projects already mounted on %s",
+					aurora.Bold(aurora.Yellow("[SKIP]")),
+					mounter.Mountpoint(),
+				))
+				return
+			}
+
+			if err = mounter.Mount(); err != nil {
+				return
+			}
+
+			m.printer.Println(fmt.Sprintf("%s Projects mounted on %s",
+				aurora.Bold(aurora.Green("[OK]")),
+				mounter.Mountpoint(),
+			))
+
+			return
+		},
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (u *Umount) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "umount",
+		Desc: "Unmount the Projects folder of the instance from the host",
+		Exec: func() (err error) {
+			mounter := *u.mounter
+			isMounted, err := mounter.IsMounted()
+			if err != nil {
+				return
+			}
+
+			if !isMounted {
+				u.printer.Println(fmt.Sprintf("%s Nothing is mounted on %s",
+					aurora.Bold(aurora.Yellow("[SKIP]")),
+					mounter.Mountpoint(),
+				))
+				return
+			}
+
+			// Without the VM the NFS server is gone, a regular unmount would hang
+			state := (*u.vmProvider).GetState()
+			if err = mounter.Umount(!state.Live); err != nil {
+				return
+			}
+
+			u.printer.Println(fmt.Sprintf("%s Projects unmounted from %s",
+				aurora.Bold(aurora.Green("[OK]")),
+				mounter.Mountpoint(),
+			))
+
+			return
+		},
+	}
+}
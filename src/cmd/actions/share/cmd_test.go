@@ -0,0 +1,93 @@
+package share
+
+import (
+	"bytes"
+	"denver/pkg/mount"
+	"denver/pkg/providers"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	state *providers.State
+}
+
+func (t *testingVM) GetState() (state *providers.State) { return t.state }
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+type testingMounter struct {
+	mounted bool
+	forced  bool
+}
+
+func (t *testingMounter) Mountpoint() string       { return "/media/jdoe/denver" }
+func (t *testingMounter) IsMounted() (bool, error) { return t.mounted, nil }
+func (t *testingMounter) Mount() error {
+	t.mounted = true
+	return nil
+}
+func (t *testingMounter) Umount(force bool) error {
+	t.mounted, t.forced = false, force
+	return nil
+}
+
+func getMounter(mounter interface{}) mount.Mounter {
+	return mounter.(mount.Mounter)
+}
+
+func TestMountFailsIfVMIsNotReady(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{state: &providers.State{Live: true}})
+	mounter := getMounter(&testingMounter{})
+
+	cmd := NewMount(&mounter, &vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "VM not ready")
+}
+
+func TestMountDetectsStaleMounts(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{state: &providers.State{}})
+	mounter := getMounter(&testingMounter{mounted: true})
+
+	cmd := NewMount(&mounter, &vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "/media/jdoe/denver is a stale mount, the VM is not running, use umount first")
+}
+
+func TestMountIsSkippedIfAlreadyMounted(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{state: &providers.State{Live: true, OsReady: true, AllSystemsReady: true}})
+	mounter := getMounter(&testingMounter{mounted: true})
+	var out bytes.Buffer
+
+	cmd := NewMount(&mounter, &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "Projects already mounted on /media/jdoe/denver")
+}
+
+func TestUmountIsForcedWhenVMIsDown(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{state: &providers.State{}})
+	testingMounter := &testingMounter{mounted: true}
+	mounter := getMounter(testingMounter)
+	var out bytes.Buffer
+
+	cmd := NewUmount(&mounter, &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.True(testingMounter.forced)
+	assert.False(testingMounter.mounted)
+}
@@ -0,0 +1,187 @@
+package snapshot
+
+import (
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"encoding/json"
+	"fmt"
+	"log"
+	"text/tabwriter"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Snapshot action
+type Snapshot struct {
+	vmProvider *providers.VMProvider
+	printer    *log.Logger
+	force      bool
+	format     string
+}
+
+// NewSnapshot returns a pointer to Snapshot
+func NewSnapshot(vmProvider *providers.VMProvider, printer *log.Logger) *Snapshot {
+	return &Snapshot{
+		vmProvider: vmProvider,
+		printer:    printer,
+		format:     "table",
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (s *Snapshot) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "snapshot",
+		Desc: "Manage the snapshots of the instance",
+		SubCommands: []cmd.DenverCommand{
+			{
+				Name:     "save <name>",
+				Desc:     "Take a snapshot of the instance",
+				Args:     cobra.ExactArgs(1),
+				ExecArgs: s.save,
+			},
+			{
+				Name: "list",
+				Desc: "List the snapshots of the instance",
+				Exec: s.list,
+				Flags: func(flags *pflag.FlagSet) {
+					flags.StringVar(&s.format, "format", "table", "Output format (table|json)")
+				},
+			},
+			{
+				Name:     "restore <name>",
+				Desc:     "Restore a snapshot of the instance",
+				Args:     cobra.ExactArgs(1),
+				ExecArgs: s.restore,
+				Flags: func(flags *pflag.FlagSet) {
+					flags.BoolVar(&s.force, "force", false, "Power off the instance if it is running")
+				},
+			},
+			{
+				Name:     "delete <name>",
+				Desc:     "Delete a snapshot of the instance",
+				Args:     cobra.ExactArgs(1),
+				ExecArgs: s.delete,
+			},
+		},
+	}
+}
+
+func (s *Snapshot) save(args []string) (err error) {
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return
+	}
+
+	if err = snapshotter.SaveSnapshot(args[0]); err != nil {
+		return
+	}
+
+	s.printer.Println(fmt.Sprintf("%s Snapshot %s has been saved",
+		aurora.Bold(aurora.Green("[OK]")),
+		args[0],
+	))
+
+	return
+}
+
+func (s *Snapshot) list() (err error) {
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return
+	}
+
+	snapshots, err := snapshotter.ListSnapshots()
+	if err != nil {
+		return
+	}
+	if snapshots == nil {
+		snapshots = []providers.Snapshot{}
+	}
+
+	// --output takes over --format, which predates it
+	if output.SetData(s.printer, snapshots) {
+		return
+	}
+
+	switch s.format {
+	case "json":
+		body, err := json.MarshalIndent(snapshots, "", "  ")
+		if err != nil {
+			return err
+		}
+		s.printer.Println(string(body))
+	case "table":
+		w := tabwriter.NewWriter(s.printer.Writer(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tDATE\tRBI VERSION")
+		for _, snapshot := range snapshots {
+			date := "-"
+			if !snapshot.Date.IsZero() {
+				date = snapshot.Date.Format("2006-01-02 15:04:05")
+			}
+			version := snapshot.RBIVersion
+			if version == "" {
+				version = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", snapshot.Name, date, version)
+		}
+		return w.Flush()
+	default:
+		return output.Errorf(output.CodeInvalidArgument, "unknown format %s", s.format)
+	}
+
+	return
+}
+
+func (s *Snapshot) restore(args []string) (err error) {
+	state := (*s.vmProvider).GetState()
+	if state.Live && !s.force {
+		return output.Errorf(output.CodeInvalidState, "VM is started, stop it first or use --force")
+	}
+
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return
+	}
+
+	if err = snapshotter.RestoreSnapshot(args[0], s.force); err != nil {
+		return
+	}
+
+	s.printer.Println(fmt.Sprintf("%s Snapshot %s has been restored",
+		aurora.Bold(aurora.Green("[OK]")),
+		args[0],
+	))
+
+	return
+}
+
+func (s *Snapshot) delete(args []string) (err error) {
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return
+	}
+
+	if err = snapshotter.DeleteSnapshot(args[0]); err != nil {
+		return
+	}
+
+	s.printer.Println(fmt.Sprintf("%s Snapshot %s has been deleted",
+		aurora.Bold(aurora.Green("[OK]")),
+		args[0],
+	))
+
+	return
+}
+
+func (s *Snapshot) getSnapshotter() (providers.Snapshotter, error) {
+	snapshotter, ok := (*s.vmProvider).(providers.Snapshotter)
+	if !ok {
+		return nil, output.Errorf(output.CodeUnsupported, "the VM provider does not support snapshots")
+	}
+
+	return snapshotter, nil
+}
@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"bytes"
+	"denver/pkg/providers"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	state     *providers.State
+	snapshots []providers.Snapshot
+	restored  string
+	forced    bool
+}
+
+func (t *testingVM) GetState() (state *providers.State) { return t.state }
+func (t *testingVM) SaveSnapshot(name string) error {
+	t.snapshots = append(t.snapshots, providers.Snapshot{Name: name})
+	return nil
+}
+func (t *testingVM) ListSnapshots() ([]providers.Snapshot, error) { return t.snapshots, nil }
+func (t *testingVM) RestoreSnapshot(name string, force bool) error {
+	t.restored, t.forced = name, force
+	return nil
+}
+func (t *testingVM) DeleteSnapshot(name string) error { return nil }
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func TestCommandFailsIfProviderDoesNotSupportSnapshots(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&providers.Testing{})
+
+	cmd := NewSnapshot(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[0].ExecArgs([]string{"before-migration"})
+	assert.EqualError(err, "the VM provider does not support snapshots")
+}
+
+func TestRestoreFailsIfVMIsOn(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		state: &providers.State{Live: true},
+	})
+
+	cmd := NewSnapshot(&vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().SubCommands[2].ExecArgs([]string{"before-migration"})
+	assert.EqualError(err, "VM is started, stop it first or use --force")
+}
+
+func TestRestoreCanBeForced(t *testing.T) {
+	assert := assert.New(t)
+	testingVM := &testingVM{
+		state: &providers.State{Live: true},
+	}
+	vm := getVMProvider(testingVM)
+	var out bytes.Buffer
+
+	cmd := NewSnapshot(&vm, log.New(&out, "", 0))
+	cmd.force = true
+
+	err := cmd.GetCommand().SubCommands[2].ExecArgs([]string{"before-migration"})
+	assert.NoError(err)
+	assert.Equal("before-migration", testingVM.restored)
+	assert.True(testingVM.forced)
+}
+
+func TestListPrintsJSON(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		snapshots: []providers.Snapshot{
+			{Name: "before-migration", Date: time.Date(2020, 2, 1, 10, 0, 0, 0, time.UTC), RBIVersion: "1.2.0"},
+		},
+	})
+	var out bytes.Buffer
+
+	cmd := NewSnapshot(&vm, log.New(&out, "", 0))
+	cmd.format = "json"
+
+	err := cmd.GetCommand().SubCommands[1].Exec()
+	assert.NoError(err)
+	assert.JSONEq(`[{"name": "before-migration", "date": "2020-02-01T10:00:00Z", "rbiVersion": "1.2.0"}]`, out.String())
+}
+
+func TestListPrintsATable(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{
+		snapshots: []providers.Snapshot{
+			{Name: "manual"},
+		},
+	})
+	var out bytes.Buffer
+
+	cmd := NewSnapshot(&vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().SubCommands[1].Exec()
+	assert.NoError(err)
+	assert.Equal("NAME    DATE  RBI VERSION\nmanual  -     -\n", out.String())
+}
@@ -2,9 +2,9 @@ package actions
 
 import (
 	"denver/cmd"
+	"denver/pkg/output"
 	"denver/pkg/providers"
 	"denver/pkg/ssh"
-	"fmt"
 	"log"
 )
 
@@ -32,7 +32,7 @@ func (s *SSH) GetCommand() cmd.DenverCommand {
 		Exec: func() error {
 			state := (*s.vmProvider).GetState()
 			if !state.AllSystemsReady {
-				return fmt.Errorf("VM not ready")
+				return output.Errorf(output.CodeNotReady, "VM not ready")
 			}
 
 			return s.ssh.Terminal()
@@ -0,0 +1,19 @@
+package actions
+
+import (
+	"denver/pkg/providers"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHFailsIfVMIsNotReady(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Live: true, OsReady: true}))
+
+	cmd := NewSSH(nil, &vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "VM not ready")
+}
@@ -12,18 +12,23 @@ import (
 	"github.com/logrusorgru/aurora"
 )
 
+// DefaultStartTimeout is used when config.starttimeout is not set
+const DefaultStartTimeout = 300 * time.Second
+
 // Start action
 type Start struct {
 	vmProvider   *providers.VMProvider
+	startTimeout *int
 	printer      *log.Logger
 	ctx          context.Context
 	checkVersion *checkversion.CheckVersion
 }
 
-// NewStart returns a pointer to Start
-func NewStart(ctx context.Context, vmProvider *providers.VMProvider, printer *log.Logger, checkVersion *checkversion.CheckVersion) *Start {
+// NewStart returns a pointer to Start, startTimeout is given in seconds
+func NewStart(ctx context.Context, vmProvider *providers.VMProvider, startTimeout *int, printer *log.Logger, checkVersion *checkversion.CheckVersion) *Start {
 	return &Start{
 		vmProvider:   vmProvider,
+		startTimeout: startTimeout,
 		printer:      printer,
 		ctx:          ctx,
 		checkVersion: checkVersion,
@@ -33,40 +38,75 @@ func NewStart(ctx context.Context, vmProvider *providers.VMProvider, printer *lo
 // GetCommand returns a valid cmd command
 func (s *Start) GetCommand() cmd.DenverCommand {
 	return cmd.DenverCommand{
-		Name: "start",
-		Desc: "Start the instance",
-		Exec: func() (err error) {
-			if err = (*s.checkVersion).CheckForUpdates(); err != nil {
-				return
-			}
-
-			if err = (*s.vmProvider).Start(); err != nil {
-				return
-			}
-
-			s.printer.Print(fmt.Sprintf("%s %s",
-				aurora.Bold(aurora.Yellow("[INFO]")),
-				"VM is starting...",
-			))
+		Name:      "start",
+		Desc:      "Start the instance",
+		Preflight: true,
+		Exec:      s.start,
+	}
+}
 
-			tick := time.Tick(250 * time.Millisecond)
-			state := (*s.vmProvider).GetState()
-
-			for !state.AllSystemsReady {
-				select {
-				case <-tick:
-					state = (*s.vmProvider).GetState()
-				case <-s.ctx.Done():
-					return
-				}
-			}
-
-			s.printer.Println(fmt.Sprintf("%s %s",
-				aurora.Bold(aurora.Green("[OK]")),
-				"VM has been started",
-			))
+func (s *Start) start() (err error) {
+	if err = (*s.checkVersion).CheckForUpdates(); err != nil {
+		return
+	}
 
+	if err = (*s.vmProvider).Start(); err != nil {
+		return
+	}
+
+	s.printer.Print(fmt.Sprintf("%s %s",
+		aurora.Bold(aurora.Yellow("[INFO]")),
+		"VM is starting...",
+	))
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout())
+	ready := (*s.vmProvider).StateStore().Wait(ctx, func(state *providers.State) bool {
+		return state.AllSystemsReady
+	})
+	cancel()
+	if !ready {
+		// Interrupted, the command must not look successful
+		if err = s.ctx.Err(); err != nil {
 			return
-		},
+		}
+
+		return s.notReady()
+	}
+
+	s.printer.Println(fmt.Sprintf("%s %s",
+		aurora.Bold(aurora.Green("[OK]")),
+		"VM has been started",
+	))
+
+	return
+}
+
+// notReady reports what the VM is still waiting for once the start timeout is reached
+func (s *Start) notReady() error {
+	state := (*s.vmProvider).GetState()
+	if !state.OsReady {
+		s.printer.Println(fmt.Sprintf("%s %s",
+			aurora.Bold(aurora.Red("[KO]")),
+			"SSH on Virtual machine OS isn't ready",
+		))
+	}
+	for _, check := range state.Checks {
+		if !check.OK {
+			s.printer.Println(fmt.Sprintf("%s Health check %s is failing: %s",
+				aurora.Bold(aurora.Red("[KO]")),
+				check.Name,
+				check.Error,
+			))
+		}
 	}
+
+	return fmt.Errorf("VM is not ready after %s", s.timeout())
+}
+
+func (s *Start) timeout() time.Duration {
+	if s.startTimeout == nil || *s.startTimeout <= 0 {
+		return DefaultStartTimeout
+	}
+
+	return time.Duration(*s.startTimeout) * time.Second
 }
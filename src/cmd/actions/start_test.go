@@ -0,0 +1,140 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"denver/cmd/actions/checkversion"
+	"denver/pkg/providers"
+	"denver/pkg/updater"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upToDateUpdater struct{}
+
+func (u upToDateUpdater) CheckIsUpdated(localVersion string) (updater.Manifest, bool, error) {
+	return updater.Manifest{}, true, nil
+}
+func (u upToDateUpdater) Update() error { return nil }
+
+type answer bool
+
+func (a answer) AskQuestion(question string) bool { return bool(a) }
+
+func getCheckVersion(vm *providers.VMProvider) *checkversion.CheckVersion {
+	return checkversion.NewCheckVersion(vm, upToDateUpdater{}, (*log.Logger)(nil), answer(true))
+}
+
+func TestStartWaitsForAllSystemsReady(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{}).
+		Script("Start", providers.BootTransitions(100*time.Millisecond)...)
+	postStarted := false
+	fake.AddPostStartAction(func() error {
+		postStarted = true
+		return nil
+	})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStart(context.Background(), &vm, nil, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.True(vm.GetState().AllSystemsReady)
+	assert.True(postStarted)
+	assert.Equal([]string{"CheckIsUpdated", "Start"}, fake.Calls())
+	assert.Contains(out.String(), "VM is starting...")
+	assert.Contains(out.String(), "VM has been started")
+}
+
+func TestStartUpdatesTheRBIFirst(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{}).
+		SetUpToDate(false).
+		Script("Start", providers.BootTransitions(0)...)
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStart(context.Background(), &vm, nil, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"CheckIsUpdated", "Update", "Start"}, fake.Calls())
+}
+
+func TestResumeKeepsTheRBIOfASavedVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Saved: true}).
+		SetUpToDate(false).
+		Script("Start", providers.BootTransitions(0)...)
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStart(context.Background(), &vm, nil, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"CheckIsUpdated", "Start"}, fake.Calls())
+}
+
+func TestStartFailsIfTheProviderFails(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{}).
+		Fail("Start", fmt.Errorf("this is fine"))
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStart(context.Background(), &vm, nil, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "this is fine")
+	assert.Empty(out.String())
+}
+
+func TestStartStopsWaitingWhenCanceled(t *testing.T) {
+	assert := assert.New(t)
+	// The OS boots but the systems never get ready
+	fake := providers.NewFake(providers.State{}).
+		Script("Start", providers.BootTransitions(10 * time.Millisecond)[:2]...)
+	vm := getVMProvider(fake)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer time.AfterFunc(600*time.Millisecond, cancel).Stop()
+	var out bytes.Buffer
+
+	cmd := NewStart(ctx, &vm, nil, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	// Interrupted, it must not look successful
+	err := cmd.GetCommand().Exec()
+	assert.Equal(context.Canceled, err)
+	assert.True(vm.GetState().OsReady)
+	assert.NotContains(out.String(), "VM has been started")
+}
+
+func TestStartGivesUpOnFailingChecks(t *testing.T) {
+	assert := assert.New(t)
+	// The OS boots but a health check never passes
+	fake := providers.NewFake(providers.State{}).
+		Script("Start",
+			providers.Transition{State: providers.State{Live: true}},
+			providers.Transition{State: providers.State{Live: true, OsReady: true, Checks: []providers.CheckResult{
+				{Name: "mysql", OK: true},
+				{Name: "nginx", Error: "nothing listens on port 80"},
+			}}},
+		)
+	vm := getVMProvider(fake)
+	timeout := 1
+	var out bytes.Buffer
+
+	cmd := NewStart(context.Background(), &vm, &timeout, log.New(&out, "", 0), getCheckVersion(&vm))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "VM is not ready after 1s")
+	assert.Contains(out.String(), "Health check nginx is failing: nothing listens on port 80")
+	assert.NotContains(out.String(), "mysql")
+	assert.NotContains(out.String(), "VM has been started")
+}
@@ -1,25 +1,38 @@
 package actions
 
 import (
+	"context"
 	"denver/cmd"
+	"denver/pkg/output"
 	"denver/pkg/providers"
 	"fmt"
 	"log"
 
 	"github.com/logrusorgru/aurora"
+	"github.com/spf13/pflag"
 )
 
 // Status action
 type Status struct {
 	vmProvider *providers.VMProvider
 	printer    *log.Logger
+	ctx        context.Context
+	watch      bool
+}
+
+// statusData is the data of status in the machine outputs, Instance is nil when the VM does not exist
+type statusData struct {
+	Instance   *providers.InstanceState `json:"instance,omitempty" yaml:"instance,omitempty"`
+	Discovered bool                     `json:"discovered" yaml:"discovered"`
+	Drift      []string                 `json:"drift" yaml:"drift"`
 }
 
 // NewStatus returns a pointer to Status
-func NewStatus(vmProvider *providers.VMProvider, printer *log.Logger) *Status {
+func NewStatus(ctx context.Context, vmProvider *providers.VMProvider, printer *log.Logger) *Status {
 	return &Status{
 		vmProvider: vmProvider,
 		printer:    printer,
+		ctx:        ctx,
 	}
 }
 
@@ -37,6 +50,11 @@ func (s *Status) GetCommand() cmd.DenverCommand {
 					aurora.Bold(aurora.Green("[OK]")),
 					"Virtual machine state is power on",
 				)
+			} else if state.Saved == true {
+				message = fmt.Sprintf("%s %s",
+					aurora.Bold(aurora.Yellow("[INFO]")),
+					"Virtual machine state is saved, resume it to continue",
+				)
 			} else {
 				message = fmt.Sprintf("%s %s",
 					aurora.Bold(aurora.Red("[KO]")),
@@ -71,7 +89,129 @@ func (s *Status) GetCommand() cmd.DenverCommand {
 			}
 			s.printer.Println(message)
 
-			return nil
+			for _, check := range state.Checks {
+				if check.OK {
+					s.printer.Println(fmt.Sprintf("%s Health check %s is passing",
+						aurora.Bold(aurora.Green("[OK]")),
+						check.Name,
+					))
+				} else {
+					s.printer.Println(fmt.Sprintf("%s Health check %s is failing: %s",
+						aurora.Bold(aurora.Red("[KO]")),
+						check.Name,
+						check.Error,
+					))
+				}
+			}
+
+			if err := s.printInstanceState(); err != nil || !s.watch {
+				return err
+			}
+
+			return s.watchState()
+		},
+		Flags: func(flags *pflag.FlagSet) {
+			flags.BoolVar(&s.watch, "watch", false, "Print the changes of the state until interrupted")
 		},
 	}
 }
+
+// watchState prints the transitions of the state as they happen, until the context is done
+func (s *Status) watchState() error {
+	events, unsubscribe := (*s.vmProvider).StateStore().Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case event := <-events:
+			// The machine outputs get each event as a document of its own
+			if streamed, err := output.Stream(s.printer, event); streamed {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			s.printer.Println(fmt.Sprintf("%s %s %s",
+				stateEventStatus(event),
+				event.Time.Format("2006-01-02 15:04:05"),
+				stateEventMessage(event),
+			))
+		case <-s.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func stateEventStatus(event providers.StateEvent) aurora.Value {
+	switch {
+	case event.Type != providers.EventWentDown:
+		return aurora.Bold(aurora.Green("[OK]"))
+	case event.State.Saved:
+		return aurora.Bold(aurora.Yellow("[INFO]"))
+	}
+
+	return aurora.Bold(aurora.Red("[KO]"))
+}
+
+func stateEventMessage(event providers.StateEvent) string {
+	switch event.Type {
+	case providers.EventPoweredOn:
+		return "Virtual machine has been powered on"
+	case providers.EventSSHUp:
+		return "Virtual machine OS is ready to handle with SSH"
+	case providers.EventSystemsReady:
+		return "Virtual machine systems are up"
+	}
+
+	if event.State.Saved {
+		return "Virtual machine has been suspended"
+	}
+	return "Virtual machine has gone down"
+}
+
+func (s *Status) printInstanceState() (err error) {
+	stater, ok := (*s.vmProvider).(providers.InstanceStater)
+	if !ok {
+		return
+	}
+
+	instanceState, err := stater.InstanceState()
+	if err != nil {
+		// The VM does not exist, there is nothing more to tell
+		return nil
+	}
+
+	data := statusData{Instance: instanceState, Discovered: instanceState.Discovered, Drift: []string{}}
+	defer func() {
+		output.SetData(s.printer, data)
+	}()
+
+	if instanceState.Discovered {
+		s.printer.Println(fmt.Sprintf("%s %s",
+			aurora.Bold(aurora.Yellow("[INFO]")),
+			"Virtual machine has been created by an older version, its state is not recorded",
+		))
+		return
+	}
+
+	s.printer.Println(fmt.Sprintf("%s Virtual machine %s created on %s from RBI %s",
+		aurora.Bold(aurora.Yellow("[INFO]")),
+		instanceState.VMUUID,
+		instanceState.CreatedAt.Format("2006-01-02 15:04:05"),
+		instanceState.RBIVersion,
+	))
+
+	drift, err := stater.InstanceDrift()
+	if err != nil {
+		return
+	}
+	data.Drift = append(data.Drift, drift...)
+	for _, d := range drift {
+		s.printer.Println(fmt.Sprintf("%s Virtual machine has been changed outside of denver: %s",
+			aurora.Bold(aurora.Red("[KO]")),
+			d,
+		))
+	}
+
+	return
+}
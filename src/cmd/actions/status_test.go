@@ -0,0 +1,142 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusTellsWhenTheVMIsReady(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Live: true, OsReady: true, AllSystemsReady: true}))
+	var out bytes.Buffer
+
+	cmd := NewStatus(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "Virtual machine state is power on")
+	assert.Contains(out.String(), "Virtual machine OS is ready to handle with SSH")
+	assert.Contains(out.String(), "Virtual machine systems are up")
+}
+
+func TestStatusListsTheHealthChecks(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Live: true, OsReady: true, Checks: []providers.CheckResult{
+		{Name: "mysql", OK: true},
+		{Name: "nginx", Error: "nothing listens on port 80"},
+	}}))
+	var out bytes.Buffer
+
+	cmd := NewStatus(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "At least one of the Virtual machine systems is down")
+	assert.Contains(out.String(), "Health check mysql is passing")
+	assert.Contains(out.String(), "Health check nginx is failing: nothing listens on port 80")
+}
+
+func TestStatusTellsWhenTheVMIsBooting(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Live: true}))
+	var out bytes.Buffer
+
+	cmd := NewStatus(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "Virtual machine state is power on")
+	assert.Contains(out.String(), "SSH on Virtual machine OS isn't ready")
+	assert.Contains(out.String(), "At least one of the Virtual machine systems is down")
+}
+
+func TestStatusTellsWhenTheVMIsSuspended(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Saved: true}))
+	var out bytes.Buffer
+
+	cmd := NewStatus(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "Virtual machine state is saved, resume it to continue")
+}
+
+func TestStatusTellsWhenTheVMIsStopped(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{}))
+	var out bytes.Buffer
+
+	cmd := NewStatus(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Contains(out.String(), "Virtual machine state is power off")
+}
+
+func TestStatusWatchPrintsTheTransitions(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{})
+	vm := getVMProvider(fake)
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+
+	cmd := NewStatus(ctx, &vm, log.New(&out, "", 0))
+	cmd.watch = true
+
+	go func() {
+		// Leave time to subscribe, then boot and suspend the VM
+		time.Sleep(100 * time.Millisecond)
+		for _, transition := range providers.BootTransitions(0) {
+			fake.StateStore().Set(transition.State)
+		}
+		fake.StateStore().Set(providers.State{Saved: true})
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(lines, 7)
+	assert.Regexp(`\[OK\].* [0-9-]+ [0-9:]+ Virtual machine has been powered on$`, lines[3])
+	assert.Contains(lines[4], "Virtual machine OS is ready to handle with SSH")
+	assert.Contains(lines[5], "Virtual machine systems are up")
+	assert.Contains(lines[6], "Virtual machine has been suspended")
+}
+
+func TestStatusWatchStreamsTheTransitionsInMachineOutputs(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{})
+	vm := getVMProvider(fake)
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+
+	cmd := NewStatus(ctx, &vm, log.New(output.NewPrinter(output.JSON, &out), "", 0))
+	cmd.watch = true
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		fake.StateStore().Set(providers.State{Live: true})
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+
+	// The lines printed before watching are kept for the final document
+	var event providers.StateEvent
+	assert.NoError(json.Unmarshal(out.Bytes(), &event))
+	assert.Equal(providers.EventPoweredOn, event.Type)
+	assert.True(event.State.Live)
+}
@@ -9,63 +9,113 @@ import (
 	"time"
 
 	"github.com/logrusorgru/aurora"
+	"github.com/spf13/pflag"
 )
 
+// DefaultStopTimeout is used when config.stoptimeout is not set
+const DefaultStopTimeout = 60 * time.Second
+
 // Stop action
 type Stop struct {
-	vmProvider *providers.VMProvider
-	printer    *log.Logger
-	ctx        context.Context
+	vmProvider  *providers.VMProvider
+	stopTimeout *int
+	printer     *log.Logger
+	ctx         context.Context
+	force       bool
 }
 
-// NewStop returns a pointer to Stop
-func NewStop(ctx context.Context, vmProvider *providers.VMProvider, printer *log.Logger) *Stop {
+// NewStop returns a pointer to Stop, stopTimeout is given in seconds
+func NewStop(ctx context.Context, vmProvider *providers.VMProvider, stopTimeout *int, printer *log.Logger) *Stop {
 	return &Stop{
-		vmProvider: vmProvider,
-		printer:    printer,
-		ctx:        ctx,
+		vmProvider:  vmProvider,
+		stopTimeout: stopTimeout,
+		printer:     printer,
+		ctx:         ctx,
 	}
 }
 
 // GetCommand returns a valid cmd command
 func (s *Stop) GetCommand() cmd.DenverCommand {
 	return cmd.DenverCommand{
-		Name: "stop",
-		Desc: "Stop the instance",
-		Exec: func() (err error) {
-			state := (*s.vmProvider).GetState()
-			if !state.Live {
-				s.printer.Println(fmt.Sprintf("%s %s",
-					aurora.Bold(aurora.Yellow("[SKIP]")),
-					"VM already stopped",
-				))
-				return
-			}
-
-			if err = (*s.vmProvider).Stop(); err != nil {
-				return err
-			}
-
-			s.printer.Print(fmt.Sprintf("%s %s",
-				aurora.Bold(aurora.Yellow("[INFO]")),
-				"VM is stopping...",
-			))
-			tick := time.Tick(250 * time.Millisecond)
-			for state.Live {
-				select {
-				case <-tick:
-					state = (*s.vmProvider).GetState()
-				case <-s.ctx.Done():
-					return
-				}
-			}
-
-			s.printer.Println(fmt.Sprintf("%s %s",
-				aurora.Bold(aurora.Green("[OK]")),
-				"VM has been stopped",
-			))
+		Name:  "stop",
+		Desc:  "Stop the instance",
+		Exec:  s.stop,
+		Flags: s.flags,
+	}
+}
+
+func (s *Stop) flags(flags *pflag.FlagSet) {
+	flags.BoolVar(&s.force, "force", false, "Power off the instance without shutting it down")
+}
+
+func (s *Stop) stop() (err error) {
+	state := (*s.vmProvider).GetState()
+	if !state.Live {
+		s.printer.Println(fmt.Sprintf("%s %s",
+			aurora.Bold(aurora.Yellow("[SKIP]")),
+			"VM already stopped",
+		))
+		return
+	}
+
+	if s.force {
+		if err = (*s.vmProvider).PowerOff(); err != nil {
+			return err
+		}
+
+		s.printer.Print(fmt.Sprintf("%s %s",
+			aurora.Bold(aurora.Yellow("[INFO]")),
+			"VM is powering off...",
+		))
+	} else {
+		if err = (*s.vmProvider).Stop(); err != nil {
+			return err
+		}
+
+		s.printer.Print(fmt.Sprintf("%s %s",
+			aurora.Bold(aurora.Yellow("[INFO]")),
+			"VM is stopping...",
+		))
+	}
 
+	states := (*s.vmProvider).StateStore()
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout())
+	stopped := states.Wait(ctx, isDown)
+	cancel()
+	if !stopped {
+		// Interrupted, the command must not look successful
+		if err = s.ctx.Err(); err != nil {
 			return
-		},
+		}
+
+		s.printer.Print(fmt.Sprintf("%s VM is still running after %s, powering off...",
+			aurora.Bold(aurora.Yellow("[INFO]")),
+			s.timeout(),
+		))
+		if err = (*s.vmProvider).PowerOff(); err != nil {
+			return
+		}
+		if !states.Wait(s.ctx, isDown) {
+			return s.ctx.Err()
+		}
+	}
+
+	s.printer.Println(fmt.Sprintf("%s %s",
+		aurora.Bold(aurora.Green("[OK]")),
+		"VM has been stopped",
+	))
+
+	return
+}
+
+func (s *Stop) timeout() time.Duration {
+	if s.stopTimeout == nil || *s.stopTimeout <= 0 {
+		return DefaultStopTimeout
 	}
+
+	return time.Duration(*s.stopTimeout) * time.Second
+}
+
+func isDown(state *providers.State) bool {
+	return !state.Live
 }
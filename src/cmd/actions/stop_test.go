@@ -0,0 +1,140 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingVM struct {
+	providers.Testing
+
+	state *providers.State
+}
+
+func (t *testingVM) GetState() *providers.State {
+	return t.state
+}
+
+func getVMProvider(provider interface{}) providers.VMProvider {
+	return provider.(providers.VMProvider)
+}
+
+func TestStopShutsDownTheVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true}).
+		Script("Stop", providers.ShutdownTransitions(0)...)
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStop(context.Background(), &vm, nil, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"Stop"}, fake.Calls())
+}
+
+func TestStopPowersOffAHungVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true}).
+		Script("PowerOff", providers.ShutdownTransitions(0)...)
+	vm := getVMProvider(fake)
+	timeout := 1
+	var out bytes.Buffer
+
+	cmd := NewStop(context.Background(), &vm, &timeout, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"Stop", "PowerOff"}, fake.Calls())
+	assert.Contains(out.String(), "VM is still running after 1s, powering off...")
+}
+
+func TestForcedStopSkipsTheShutdown(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true}).
+		Script("PowerOff", providers.ShutdownTransitions(0)...)
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStop(context.Background(), &vm, nil, log.New(&out, "", 0))
+	cmd.force = true
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([]string{"PowerOff"}, fake.Calls())
+}
+
+func TestStopWaitsForTheShutdown(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true, OsReady: true, AllSystemsReady: true}).
+		Script("Stop", providers.ShutdownTransitions(300*time.Millisecond)...)
+	preStopped := false
+	fake.AddPreStopAction(func() error {
+		preStopped = true
+		return nil
+	})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStop(context.Background(), &vm, nil, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.True(preStopped)
+	assert.False(vm.GetState().Live)
+	assert.Equal([]string{"Stop"}, fake.Calls())
+	assert.Contains(out.String(), "VM has been stopped")
+}
+
+func TestStopStopsWaitingWhenCanceled(t *testing.T) {
+	assert := assert.New(t)
+	// The VM never goes down
+	fake := providers.NewFake(providers.State{Live: true})
+	vm := getVMProvider(fake)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer time.AfterFunc(300*time.Millisecond, cancel).Stop()
+	var out bytes.Buffer
+
+	cmd := NewStop(ctx, &vm, nil, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.Equal(context.Canceled, err)
+	assert.Equal([]string{"Stop"}, fake.Calls())
+	assert.NotContains(out.String(), "VM has been stopped")
+}
+
+func TestStopSkipsAStoppedVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewStop(context.Background(), &vm, nil, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Empty(fake.Calls())
+	assert.Contains(out.String(), "VM already stopped")
+}
+
+func TestStopFailsIfThePowerOffFails(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true}).
+		Fail("PowerOff", fmt.Errorf("this is fine"))
+	vm := getVMProvider(fake)
+	timeout := 1
+	var out bytes.Buffer
+
+	cmd := NewStop(context.Background(), &vm, &timeout, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "this is fine")
+	assert.Equal([]string{"Stop", "PowerOff"}, fake.Calls())
+}
@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"context"
+	"denver/cmd"
+	"denver/pkg/output"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// Suspend action
+type Suspend struct {
+	vmProvider *providers.VMProvider
+	printer    *log.Logger
+	ctx        context.Context
+}
+
+// NewSuspend returns a pointer to Suspend
+func NewSuspend(ctx context.Context, vmProvider *providers.VMProvider, printer *log.Logger) *Suspend {
+	return &Suspend{
+		vmProvider: vmProvider,
+		printer:    printer,
+		ctx:        ctx,
+	}
+}
+
+// GetCommand returns a valid cmd command
+func (s *Suspend) GetCommand() cmd.DenverCommand {
+	return cmd.DenverCommand{
+		Name: "suspend",
+		Desc: "Save the state of the instance and stop it",
+		Exec: func() (err error) {
+			state := (*s.vmProvider).GetState()
+			if !state.Live {
+				s.printer.Println(fmt.Sprintf("%s %s",
+					aurora.Bold(aurora.Yellow("[SKIP]")),
+					"VM already stopped",
+				))
+				return
+			}
+
+			suspender, ok := (*s.vmProvider).(providers.Suspender)
+			if !ok {
+				return output.Errorf(output.CodeUnsupported, "the VM provider does not support suspending")
+			}
+
+			s.printer.Print(fmt.Sprintf("%s %s",
+				aurora.Bold(aurora.Yellow("[INFO]")),
+				"VM is suspending...",
+			))
+			if err = suspender.Suspend(); err != nil {
+				return
+			}
+
+			if !(*s.vmProvider).StateStore().Wait(s.ctx, isDown) {
+				return s.ctx.Err()
+			}
+
+			s.printer.Println(fmt.Sprintf("%s %s",
+				aurora.Bold(aurora.Green("[OK]")),
+				"VM has been suspended",
+			))
+
+			return
+		},
+	}
+}
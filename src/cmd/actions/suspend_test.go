@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"denver/pkg/providers"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuspendFailsIfProviderDoesNotSupportIt(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(&testingVM{state: &providers.State{Live: true}})
+
+	cmd := NewSuspend(context.Background(), &vm, (*log.Logger)(nil))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "the VM provider does not support suspending")
+}
+
+func TestSuspendSavesTheVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true, OsReady: true, AllSystemsReady: true}).
+		Script("Suspend", providers.Transition{Delay: 300 * time.Millisecond, State: providers.State{Saved: true}})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewSuspend(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.True(vm.GetState().Saved)
+	assert.Contains(out.String(), "VM has been suspended")
+}
+
+func TestSuspendStopsWaitingWhenCanceled(t *testing.T) {
+	assert := assert.New(t)
+	// The VM is never saved
+	fake := providers.NewFake(providers.State{Live: true, OsReady: true, AllSystemsReady: true})
+	vm := getVMProvider(fake)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer time.AfterFunc(300*time.Millisecond, cancel).Stop()
+	var out bytes.Buffer
+
+	cmd := NewSuspend(ctx, &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.Equal(context.Canceled, err)
+	assert.NotContains(out.String(), "VM has been suspended")
+}
+
+func TestSuspendSkipsAStoppedVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{})
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewSuspend(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Empty(fake.Calls())
+	assert.Contains(out.String(), "VM already stopped")
+}
+
+func TestSuspendFailsIfTheProviderFails(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Live: true}).
+		Fail("Suspend", fmt.Errorf("this is fine"))
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewSuspend(context.Background(), &vm, log.New(&out, "", 0))
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "this is fine")
+	assert.True(vm.GetState().Live)
+}
+
+func TestResumeStartsTheSuspendedVM(t *testing.T) {
+	assert := assert.New(t)
+	fake := providers.NewFake(providers.State{Saved: true}).
+		Script("Start", providers.BootTransitions(50*time.Millisecond)...)
+	vm := getVMProvider(fake)
+	var out bytes.Buffer
+
+	cmd := NewResume(&vm, NewStart(context.Background(), &vm, nil, log.New(&out, "", 0), getCheckVersion(&vm)))
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.True(fake.Resumed())
+	assert.True(vm.GetState().AllSystemsReady)
+	assert.Contains(out.String(), "VM has been started")
+}
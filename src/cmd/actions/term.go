@@ -1,7 +1,9 @@
 package actions
 
 import (
+	"context"
 	"denver/cmd"
+	"denver/pkg/output"
 	"denver/pkg/providers"
 	"denver/pkg/util/executor"
 	"fmt"
@@ -12,11 +14,11 @@ import (
 
 // Term action
 type Term struct {
-	workingDirectory                      string
-	executor                              *executor.Executor
-	user, ip, terminal, terminalArguments *string
-	vmProvider                            *providers.VMProvider
-	printer                               *log.Logger
+	workingDirectory                            string
+	executor                                    executor.Executor
+	user, ip, port, terminal, terminalArguments *string
+	vmProvider                                  *providers.VMProvider
+	printer                                     *log.Logger
 }
 
 // This variables has been created to be set during compilation :)
@@ -25,12 +27,13 @@ var darwinTerm = "alacritty-darwin-0.4.1"
 var linuxTerm = "alacritty-linux-0.4.1"
 
 // NewTerm returns a pointer to Term
-func NewTerm(workingDirectory string, user, ip, terminal, terminalArguments *string, vmProvider *providers.VMProvider, printer *log.Logger) *Term {
+func NewTerm(ctx context.Context, workingDirectory string, user, ip, port, terminal, terminalArguments *string, vmProvider *providers.VMProvider, printer *log.Logger) *Term {
 	return &Term{
 		workingDirectory:  workingDirectory,
-		executor:          executor.NewExecutor(),
+		executor:          executor.NewDefaultExecutor(ctx),
 		user:              user,
 		ip:                ip,
+		port:              port,
 		terminal:          terminal,
 		terminalArguments: terminalArguments,
 		vmProvider:        vmProvider,
@@ -46,7 +49,7 @@ func (t *Term) GetCommand() cmd.DenverCommand {
 		Exec: func() error {
 			state := (*t.vmProvider).GetState()
 			if !state.AllSystemsReady {
-				return fmt.Errorf("VM not ready")
+				return output.Errorf(output.CodeNotReady, "VM not ready")
 			}
 
 			var command []string
@@ -74,7 +77,7 @@ func (t *Term) GetCommand() cmd.DenverCommand {
 				switch runtime.GOOS {
 				case "windows":
 				case "linux":
-					return fmt.Errorf("not supported on this system")
+					return output.Errorf(output.CodeUnsupported, "not supported on this system")
 				case "darwin":
 					defaultTerm = "iterm2.sh"
 				}
@@ -85,7 +88,7 @@ func (t *Term) GetCommand() cmd.DenverCommand {
 			if tArguments != "" {
 				arguments = append(arguments, tArguments)
 			}
-			arguments = append(arguments, "ssh", "-i", ".ssh/id_rsa", "-o", "StrictHostKeyChecking=no", user)
+			arguments = append(arguments, "ssh", "-i", ".ssh/id_rsa", "-o", "StrictHostKeyChecking=no", "-p", *t.port, user)
 			command = append(command, arguments...)
 			if _, err := t.executor.Execute(command); err != nil {
 				return err
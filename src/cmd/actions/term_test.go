@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"context"
+	"denver/pkg/providers"
+	"denver/pkg/util/executor"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type terminalExecutor struct {
+	commands [][]string
+}
+
+func (e *terminalExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, executor.Options{})
+}
+func (e *terminalExecutor) ExecuteWithOptions(args []string, options executor.Options) (string, error) {
+	e.commands = append(e.commands, args)
+	return "", nil
+}
+
+func getTerm(vm *providers.VMProvider, terminal, arguments string) *Term {
+	user, ip, port := "ldevuser", "10.10.10.10", "22"
+	return NewTerm(context.Background(), "/denver", &user, &ip, &port, &terminal, &arguments, vm, (*log.Logger)(nil))
+}
+
+func TestTermFailsIfVMIsNotReady(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Live: true}))
+
+	cmd := getTerm(&vm, "xterm", "")
+
+	err := cmd.GetCommand().Exec()
+	assert.EqualError(err, "VM not ready")
+}
+
+func TestTermRunsSSHInTheConfiguredTerminal(t *testing.T) {
+	assert := assert.New(t)
+	vm := getVMProvider(providers.NewFake(providers.State{Live: true, OsReady: true, AllSystemsReady: true}))
+	executor := &terminalExecutor{}
+
+	cmd := getTerm(&vm, "xterm", "-e")
+	cmd.executor = executor
+
+	err := cmd.GetCommand().Exec()
+	assert.NoError(err)
+	assert.Equal([][]string{{
+		"xterm", "-e",
+		"ssh", "-i", ".ssh/id_rsa", "-o", "StrictHostKeyChecking=no", "-p", "22", "ldevuser@10.10.10.10",
+	}}, executor.commands)
+}
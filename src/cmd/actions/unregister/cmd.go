@@ -2,8 +2,8 @@ package unregister
 
 import (
 	"denver/cmd"
+	"denver/pkg/output"
 	"denver/pkg/providers"
-	"fmt"
 	"log"
 )
 
@@ -29,7 +29,7 @@ func (d *Unregister) GetCommand() cmd.DenverCommand {
 		Exec: func() error {
 			state := (*d.vmProvider).GetState()
 			if state.Live {
-				return fmt.Errorf("VM is started, stop it first")
+				return output.Errorf(output.CodeInvalidState, "VM is started, stop it first")
 			}
 
 			return (*d.vmProvider).Unregister()
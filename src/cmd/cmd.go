@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Version compiled
@@ -18,20 +19,66 @@ type Action interface {
 	GetCommand() DenverCommand
 }
 
+// ConfigOnlyAnnotation flags commands which do not need a VM provider
+const ConfigOnlyAnnotation = "denver/config-only"
+
+// PreflightAnnotation flags commands which check the host before operating the VM
+const PreflightAnnotation = "denver/preflight"
+
 // DenverCommand contains a CLI command
 type DenverCommand struct {
 	Name string
 	Desc string
 	Exec func() error
+	// ExecArgs replaces Exec for commands expecting positional arguments
+	ExecArgs    func(args []string) error
+	Args        cobra.PositionalArgs
+	Flags       func(flags *pflag.FlagSet)
+	SubCommands []DenverCommand
+	// ConfigOnly commands only need the configuration to be loaded
+	ConfigOnly bool
+	// Preflight commands create, start or update the VM, the host is checked first
+	Preflight bool
 }
 
 // CreateCobraCommand returns a a cobra command from an DenverCommand
 func CreateCobraCommand(command DenverCommand) *cobra.Command {
-	return &cobra.Command{
+	cobraCmd := &cobra.Command{
 		Use:   command.Name,
 		Short: command.Desc,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		Args:  command.Args,
+	}
+
+	if command.Exec != nil {
+		cobraCmd.RunE = func(cmd *cobra.Command, args []string) error {
 			return command.Exec()
-		},
+		}
+	}
+
+	if command.ExecArgs != nil {
+		cobraCmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return command.ExecArgs(args)
+		}
+	}
+
+	if command.Flags != nil {
+		command.Flags(cobraCmd.Flags())
+	}
+
+	cobraCmd.Annotations = map[string]string{}
+	if command.ConfigOnly {
+		cobraCmd.Annotations[ConfigOnlyAnnotation] = "true"
+	}
+	if command.Preflight {
+		cobraCmd.Annotations[PreflightAnnotation] = "true"
 	}
+
+	for _, subCommand := range command.SubCommands {
+		if command.ConfigOnly {
+			subCommand.ConfigOnly = true
+		}
+		cobraCmd.AddCommand(CreateCobraCommand(subCommand))
+	}
+
+	return cobraCmd
 }
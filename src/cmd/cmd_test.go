@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandKeepsAllItsAnnotations(t *testing.T) {
+	assert := assert.New(t)
+
+	c := CreateCobraCommand(DenverCommand{Name: "check", ConfigOnly: true, Preflight: true})
+	assert.Equal(map[string]string{ConfigOnlyAnnotation: "true", PreflightAnnotation: "true"}, c.Annotations)
+}
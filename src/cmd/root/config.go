@@ -0,0 +1,160 @@
+package root
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeConfigStrings sets key of the YAML configuration file to values, key being a path like viper's
+// whose last section must exist in block style. Only the lines of key are rewritten, the rest of the file is kept as is
+func writeConfigStrings(path, key string, values []string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var document yaml.Node
+	if err = yaml.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("unable to read %s: %s", path, err)
+	}
+
+	names := strings.Split(key, ".")
+	section := &document
+	if len(document.Content) > 0 {
+		section = document.Content[0]
+	}
+	// sectionKey is the key node of the section, nil for the document
+	var sectionKey *yaml.Node
+	for i, name := range names[:len(names)-1] {
+		if sectionKey, section = configValue(section, name); section == nil || section.Kind != yaml.MappingNode || len(section.Content) == 0 {
+			return fmt.Errorf("unable to set %s in %s, there is no %s section", key, path, strings.Join(names[:i+1], "."))
+		}
+	}
+	if section.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("unable to set %s in %s, the %s section is written in flow style, edit the file instead",
+			key, path, strings.Join(names[:len(names)-1], "."))
+	}
+
+	newline := "\n"
+	if strings.Contains(string(body), "\r\n") {
+		newline = "\r\n"
+	}
+	lines := strings.SplitAfter(string(body), "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += newline
+	}
+
+	// Lines and columns of the nodes start at 1
+	indent := strings.Repeat(" ", section.Content[0].Column-1)
+	replaced := []string{indent + names[len(names)-1] + ":"}
+	if len(values) == 0 {
+		replaced[0] += " []"
+	}
+	for _, value := range values {
+		replaced = append(replaced, fmt.Sprintf("%s  - '%s'", indent, strings.Replace(value, "'", "''", -1)))
+	}
+	for i := range replaced {
+		replaced[i] += newline
+	}
+
+	// A new key goes at the end of the section, an existing one is replaced with its value
+	keys := blockKeys(document.Content, nil)
+	from := valueEnd(lines, keys, sectionKey)
+	to := from
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		if strings.EqualFold(section.Content[i].Value, names[len(names)-1]) {
+			from, to = section.Content[i].Line-1, valueEnd(lines, keys, section.Content[i])
+		}
+	}
+
+	content := strings.Join(lines[:from], "") + strings.Join(replaced, "") + strings.Join(lines[to:], "")
+
+	// The file is replaced at once, a failure leaves the former one untouched
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.WriteString(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// configValue returns the key and the value of name in mapping, whose keys are case insensitive like viper's
+func configValue(mapping *yaml.Node, name string) (key *yaml.Node, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, name) {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+// blockKeys returns the keys of the block mappings of nodes, the flow ones may span lines
+// in any way and are left out
+func blockKeys(nodes []*yaml.Node, keys []*yaml.Node) []*yaml.Node {
+	for _, node := range nodes {
+		if node.Style&yaml.FlowStyle != 0 {
+			continue
+		}
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i < len(node.Content); i += 2 {
+				keys = append(keys, node.Content[i])
+			}
+		}
+		keys = blockKeys(node.Content, keys)
+	}
+
+	return keys
+}
+
+// valueEnd returns the number of lines up to the end of the value of key, the whole document
+// for a nil key. The value ends before the next key which is not nested in it, the blank lines
+// and the comments of this key going with it
+func valueEnd(lines []string, keys []*yaml.Node, key *yaml.Node) int {
+	end := len(lines)
+	column := 1
+	if key != nil {
+		column = key.Column
+		for _, next := range keys {
+			if next.Line > key.Line && next.Column <= key.Column && next.Line-1 < end {
+				end = next.Line - 1
+			}
+		}
+	}
+
+	for ; end > 0; end-- {
+		line := strings.TrimRight(lines[end-1], "\r\n")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed != "" && (!strings.HasPrefix(trimmed, "#") || len(line)-len(trimmed) >= column) {
+			break
+		}
+	}
+
+	return end
+}
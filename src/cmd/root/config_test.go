@@ -0,0 +1,151 @@
+package root
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `config:
+  channel: 'stable'
+
+instance:
+  name: 'denver'
+  # Forwarded with denver port
+  ports: ['8080:80']
+  localip: '10.10.10.10'
+
+instances:
+  legacy:
+    vmem: 2048 # enough for PHP 5
+    ports:
+      - '8080:80'
+      - '8443:443'
+
+    # Keep it last
+    localip: '10.10.20.10'
+  php7:
+    vmem: 1024
+`
+
+func writeTestConfig(t *testing.T) string {
+	f, err := ioutil.TempFile("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err = f.WriteString(testConfig); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestWriteConfigStringsOnlyRewritesTheKey(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		key    string
+		values []string
+		old    string
+		new    string
+	}{
+		{
+			"instance.ports",
+			[]string{"8080:80", "3306:3306"},
+			"  ports: ['8080:80']\n",
+			"  ports:\n    - '8080:80'\n    - '3306:3306'\n",
+		},
+		{
+			"instances.legacy.ports",
+			nil,
+			"    ports:\n      - '8080:80'\n      - '8443:443'\n",
+			"    ports: []\n",
+		},
+		{
+			"instances.php7.ports",
+			[]string{"9000:9000"},
+			"    vmem: 1024\n",
+			"    vmem: 1024\n    ports:\n      - '9000:9000'\n",
+		},
+	}
+
+	for _, testcase := range testcases {
+		path := writeTestConfig(t)
+		defer os.Remove(path)
+
+		assert.NoError(writeConfigStrings(path, testcase.key, testcase.values))
+		body, err := ioutil.ReadFile(path)
+		assert.NoError(err)
+		assert.Equal(strings.Replace(testConfig, testcase.old, testcase.new, 1), string(body))
+	}
+}
+
+func TestWriteConfigStringsFindsTheEndOfTheValues(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		config   string
+		key      string
+		expected string
+	}{
+		{
+			// A flow sequence spanning lines
+			"instance:\n  ports: ['8080:80',\n    '8443:443']\n  localip: '10.10.10.10'\n",
+			"instance.ports",
+			"instance:\n  ports:\n    - '9000:9000'\n  localip: '10.10.10.10'\n",
+		},
+		{
+			// A block scalar ends the section
+			"instance:\n  name: 'denver'\n  motd: |\n    Welcome\n\n    # not a comment\n\n# Providers\nproviders: {}\n",
+			"instance.ports",
+			"instance:\n  name: 'denver'\n  motd: |\n    Welcome\n\n    # not a comment\n  ports:\n    - '9000:9000'\n\n# Providers\nproviders: {}\n",
+		},
+		{
+			// A flow mapping spanning lines ends the section
+			"instance:\n  healthchecks: [{name: web,\n    port: 80}\n  ]\nconfig:\n  channel: 'stable'\n",
+			"instance.ports",
+			"instance:\n  healthchecks: [{name: web,\n    port: 80}\n  ]\n  ports:\n    - '9000:9000'\nconfig:\n  channel: 'stable'\n",
+		},
+	}
+
+	for _, testcase := range testcases {
+		path := writeTestConfig(t)
+		defer os.Remove(path)
+		assert.NoError(ioutil.WriteFile(path, []byte(testcase.config), 0644))
+
+		assert.NoError(writeConfigStrings(path, testcase.key, []string{"9000:9000"}))
+		body, err := ioutil.ReadFile(path)
+		assert.NoError(err)
+		assert.Equal(testcase.expected, string(body))
+	}
+}
+
+func TestWriteConfigStringsRefusesFlowSections(t *testing.T) {
+	assert := assert.New(t)
+	path := writeTestConfig(t)
+	defer os.Remove(path)
+	config := "instance: {name: 'denver', ports: ['8080:80']}\n"
+	assert.NoError(ioutil.WriteFile(path, []byte(config), 0644))
+
+	err := writeConfigStrings(path, "instance.ports", []string{"9000:9000"})
+	assert.EqualError(err, "unable to set instance.ports in "+path+", the instance section is written in flow style, edit the file instead")
+
+	body, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(config, string(body))
+}
+
+func TestWriteConfigStringsNeedsTheSection(t *testing.T) {
+	assert := assert.New(t)
+	path := writeTestConfig(t)
+	defer os.Remove(path)
+
+	err := writeConfigStrings(path, "instances.missing.ports", []string{"8080:80"})
+	assert.EqualError(err, "unable to set instances.missing.ports in "+path+", there is no instances.missing section")
+
+	body, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(testConfig, string(body))
+}
@@ -5,20 +5,36 @@ import (
 	"denver/cmd"
 	"denver/cmd/actions"
 	"denver/cmd/actions/checkversion"
+	"denver/cmd/actions/disk"
+	"denver/cmd/actions/export"
+	"denver/cmd/actions/info"
+	"denver/cmd/actions/instances"
+	"denver/cmd/actions/network"
+	"denver/cmd/actions/port"
+	"denver/cmd/actions/resize"
+	"denver/cmd/actions/share"
+	"denver/cmd/actions/snapshot"
 	"denver/cmd/actions/unregister"
+	"denver/pkg/guest"
+	"denver/pkg/hooks"
+	"denver/pkg/mount"
 	"denver/pkg/notify"
+	"denver/pkg/output"
 	"denver/pkg/providers"
 	"denver/pkg/ssh"
 	"denver/pkg/storage/http"
 	"denver/pkg/updater"
 	"denver/pkg/user"
+	"denver/pkg/util"
 	"denver/pkg/util/compressor"
+	"denver/pkg/util/executor"
 	"denver/structs"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/mitchellh/mapstructure"
@@ -33,17 +49,28 @@ import (
 type Denver struct {
 	workingDirectory string
 	printer          *log.Logger
+	output           *output.Printer
 	config           *structs.Denver
+	instance         *structs.InstanceConf
 	availableActions []cmd.Action
+	configFunc       []func() error
 	bootstrapFunc    []func() error
 	vMProvider       providers.VMProvider
+	executor         executor.Executor
+	recorder         *executor.RecordingExecutor
 	ssh              *ssh.SSH
+	mounter          mount.Mounter
 	updater          updater.Updater
 	ctx              context.Context
 	notify           notify.Notify
 }
 
 var configFile string
+var instanceName string
+var dryRun bool
+var recordFile string
+var outputFormat string
+var unattended bool
 
 // New returns a pointer to Denver
 func New(ctx context.Context, workingDirectory string) *Denver {
@@ -53,20 +80,23 @@ func New(ctx context.Context, workingDirectory string) *Denver {
 		printer:          log.New(os.Stdout, "", 0),
 		config:           structs.NewDenverConfig(),
 		ssh:              &ssh.SSH{},
-		updater: updater.NewDefaultUpdater(
-			ctx,
-			workingDirectory,
-			fmt.Sprintf("%s/%s/manifest.json", cmd.UpdatePath, runtime.GOOS),
-			[]string{
-				"denver",
-				"tools",
-				filepath.Join("conf", "config.dist.yml"),
-			},
-			compressor.NewMultiCompressor(),
-			&http.HTTP{},
+		updater: updater.NewDryRunUpdater(
+			updater.NewDefaultUpdater(
+				ctx,
+				workingDirectory,
+				fmt.Sprintf("%s/%s/manifest.json", cmd.UpdatePath, runtime.GOOS),
+				[]string{
+					"denver",
+					"tools",
+					filepath.Join("conf", "config.dist.yml"),
+				},
+				compressor.NewMultiCompressor(),
+				&http.HTTP{},
+			),
+			&dryRun,
 		),
 		ctx:    ctx,
-		notify: notify.CliQuestion{},
+		notify: notify.NewUnattended(notify.CliQuestion{}, &unattended),
 	}
 }
 
@@ -77,24 +107,71 @@ func (s *Denver) Execute() int {
 
 	rootCmd := s.getRootCommand()
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file")
+	rootCmd.PersistentFlags().StringVar(&instanceName, "instance", "", "Instance to operate (default instance when empty)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the commands changing the instance instead of running them")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "Record the commands and their outputs to a test fixture")
+	_ = rootCmd.PersistentFlags().MarkHidden("record")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", string(output.Text), "Output format (text|json|yaml), json and yaml write a single document once the command is done")
 	for _, action := range s.availableActions {
 		rootCmd.AddCommand(cmd.CreateCobraCommand(action.GetCommand()))
 	}
 
-	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) (err error) {
+	rootCmd.PersistentPreRunE = func(c *cobra.Command, args []string) (err error) {
+		if err = s.setOutput(); err != nil {
+			return
+		}
+		for _, f := range s.configFunc {
+			if err = f(); err != nil {
+				return
+			}
+		}
+		if c.Annotations[cmd.ConfigOnlyAnnotation] == "true" {
+			return
+		}
 		for _, f := range s.bootstrapFunc {
 			if err = f(); err != nil {
 				return
 			}
 		}
+		if c.Annotations[cmd.PreflightAnnotation] == "true" {
+			return s.preflight()
+		}
 		return
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	c, err := rootCmd.ExecuteC()
+	s.ssh.Close()
+	if s.recorder != nil {
+		if saveErr := s.recorder.Save(recordFile); saveErr != nil {
+			log.Println(saveErr)
+		}
+	}
+	if dryRunExecutor, ok := s.executor.(*executor.DryRunExecutor); ok {
+		s.printer.Println(fmt.Sprintf("%s Dry-run, %d command(s) have not been run",
+			aurora.Bold(aurora.Yellow("[INFO]")),
+			len(dryRunExecutor.Commands()),
+		))
+	}
+
+	// The arguments are checked before the output is set
+	if s.output == nil {
+		_ = s.setOutput()
+	}
+	if s.output != nil && s.output.Machine() && c != nil {
+		if s.vMProvider != nil {
+			s.output.SetState(s.vMProvider.GetState())
+		}
+		command := strings.TrimPrefix(c.CommandPath(), rootCmd.Name()+" ")
+		if flushErr := s.output.Flush(command, cmd.Version, err); flushErr != nil {
+			log.Println(flushErr)
+		}
+	} else if err != nil {
 		s.printer.Println(fmt.Sprintf("%s %s",
 			aurora.Bold(aurora.Red("[KO]")),
 			err.Error(),
 		))
+	}
+	if err != nil {
 		return 1
 	}
 
@@ -146,39 +223,180 @@ func (s *Denver) initConfig() (err error) {
 	})
 }
 
+func (s *Denver) setInstance() (err error) {
+	s.instance, err = s.config.GetInstance(instanceName)
+	return
+}
+
+// saveInstancePorts writes the port forwards of the current instance to the configuration file
+func (s *Denver) saveInstancePorts(ports []string) error {
+	if dryRun {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s.ports", s.instance.ConfigKey)
+	viper.Set(key, ports)
+
+	// viper would write the file again from what it has read, dropping its comments and its order
+	switch filepath.Ext(viper.ConfigFileUsed()) {
+	case ".yml", ".yaml":
+		if err := writeConfigStrings(viper.ConfigFileUsed(), key, ports); err != nil {
+			return err
+		}
+	default:
+		if err := viper.WriteConfig(); err != nil {
+			return err
+		}
+	}
+
+	s.instance.Ports = ports
+	return nil
+}
+
+// setOutput selects the output format, the machine ones go without colors, progress bars nor questions
+func (s *Denver) setOutput() (err error) {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return
+	}
+
+	s.output = output.NewPrinter(format, os.Stdout)
+	s.printer.SetOutput(s.output)
+	if s.output.Machine() {
+		// The logs would break the document
+		log.SetOutput(os.Stderr)
+		util.DisableProgress()
+		unattended = true
+	}
+
+	return
+}
+
+// setExecutor selects how the commands are run, a dry-run only runs the ones reading the host state
+func (s *Denver) setExecutor() (err error) {
+	s.executor = executor.NewDefaultExecutor(s.ctx)
+	if recordFile != "" {
+		// Fixtures must not depend on where denver is installed
+		s.recorder = executor.NewRecordingExecutor(s.executor, map[string]string{"dir": s.workingDirectory})
+		s.executor = s.recorder
+	}
+	if dryRun {
+		s.executor = executor.NewDryRunExecutor(s.executor, func(args []string) bool {
+			return providers.IsReadOnlyCommand(args) || mount.IsReadOnlyCommand(args)
+		}, s.printer)
+	}
+
+	return
+}
+
 func (s *Denver) setVMProvider() (err error) {
 	var provider structs.Provider
 	var ok bool
-	if provider, ok = s.config.Providers[s.config.Instance.Provider]; !ok {
-		return fmt.Errorf("VM Provider %s not found", s.config.Instance.Provider)
+	if provider, ok = s.config.Providers[s.instance.Provider]; !ok {
+		return fmt.Errorf("VM Provider %s not found", s.instance.Provider)
 	}
 
 	if s.vMProvider, err = providers.GetVMProvider(
 		s.ctx,
 		provider,
-		s.config.Instance,
-		s.config.UserInfo.Userdatasize,
+		s.instance,
 		s.config.Config.Channel,
 		s.config.Config.RBIURL,
 		s.workingDirectory,
+		s.executor,
 	); err != nil {
 		return
 	}
 
+	// What SSH sessions would change inside the instance can't be previewed, it is skipped
+	addGuestAction := func(f func() error) {
+		if !dryRun {
+			s.vMProvider.AddPostStartAction(f)
+		}
+	}
+
 	u := user.NewUser(s.config.UserInfo, s.ssh)
-	s.vMProvider.AddPostStartAction(func() (err error) {
+	addGuestAction(func() (err error) {
 		return u.SetGitUser()
 	})
 
-	s.vMProvider.AddPostStartAction(func() (err error) {
+	addGuestAction(func() (err error) {
 		return u.SetUserKey()
 	})
 
+	if s.instance.Automount {
+		s.vMProvider.AddPostStartAction(func() (err error) {
+			if isMounted, err := s.mounter.IsMounted(); err != nil || isMounted {
+				return err
+			}
+			return s.mounter.Mount()
+		})
+	}
+
+	if suspender, ok := s.vMProvider.(providers.Suspender); ok {
+		c := guest.NewClock(s.ssh)
+		addGuestAction(func() (err error) {
+			if !suspender.Resumed() {
+				return
+			}
+			return c.Sync()
+		})
+	}
+
+	if grower, ok := s.vMProvider.(providers.DiskGrower); ok {
+		d := guest.NewDisk(s.ssh, s.instance.Sshuser)
+		addGuestAction(func() (err error) {
+			return d.GrowFilesystem(grower)
+		})
+	}
+
+	// The configured hooks come after the post-start actions above and before the unmount,
+	// so that the share is still there for them
+	if err = hooks.NewHooks(s.executor, s.ssh).Attach(s.instance.Hooks, s.vMProvider, !dryRun); err != nil {
+		return
+	}
+
+	s.vMProvider.AddPreStopAction(func() error {
+		umountShare(s.mounter)
+		return nil
+	})
+
 	return
 }
 
+// umountShare unmounts the share before the VM goes down, forcibly when it is busy. The VM is
+// stopped anyway, a share left behind is only logged
+func umountShare(mounter mount.Mounter) {
+	isMounted, err := mounter.IsMounted()
+	if err != nil {
+		log.Printf("Unable to tell whether %s is mounted: %s", mounter.Mountpoint(), err)
+		return
+	}
+	if !isMounted {
+		return
+	}
+
+	if err = mounter.Umount(false); err == nil {
+		return
+	}
+	log.Printf("Unable to unmount %s, forcing it: %s", mounter.Mountpoint(), err)
+
+	if err = mounter.Umount(true); err != nil {
+		log.Printf("Unable to unmount %s: %s", mounter.Mountpoint(), err)
+	}
+}
+
+// preflight checks that the host can run the VM, only before creating, starting or updating it
+func (s *Denver) preflight() error {
+	if preflighter, ok := s.vMProvider.(providers.Preflighter); ok {
+		return preflighter.Preflight()
+	}
+
+	return nil
+}
+
 func (s *Denver) setSSH() (err error) {
-	sshVal, err := ssh.NewSSH(s.config.Instance.Localip, s.workingDirectory)
+	sshVal, err := ssh.NewSSH(s.instance.Localip, s.instance.Sshuser, s.instance.Sshport, s.workingDirectory)
 	if err != nil {
 		return
 	}
@@ -187,8 +405,25 @@ func (s *Denver) setSSH() (err error) {
 	return
 }
 
+func (s *Denver) setMounter() (err error) {
+	s.mounter = mount.NewDefaultMounter(
+		s.instance.Localip,
+		s.instance.Sshuser,
+		s.instance.Mountpoint,
+		s.instance.Name,
+		s.executor,
+	)
+
+	return
+}
+
 func (s *Denver) initProbe() (err error) {
-	return providers.NewProbe(s.ctx, s.ssh).Start(s.vMProvider)
+	probe, err := providers.NewProbe(s.ctx, s.ssh, s.instance.Healthchecks)
+	if err != nil {
+		return
+	}
+
+	return probe.Start(s.vMProvider)
 }
 
 func setLog() {
@@ -198,16 +433,28 @@ func setLog() {
 }
 
 func (s *Denver) addBootstrapFunc() {
-	s.bootstrapFunc = append(s.bootstrapFunc,
+	s.configFunc = append(s.configFunc,
 		func() (err error) {
 			return s.initConfig()
 		},
+		func() (err error) {
+			return s.setInstance()
+		},
+	)
+
+	s.bootstrapFunc = append(s.bootstrapFunc,
+		func() (err error) {
+			return s.setExecutor()
+		},
 		func() (err error) {
 			return s.setVMProvider()
 		},
 		func() (err error) {
 			return s.setSSH()
 		},
+		func() (err error) {
+			return s.setMounter()
+		},
 		func() (err error) {
 			return s.initProbe()
 		},
@@ -216,16 +463,32 @@ func (s *Denver) addBootstrapFunc() {
 
 func (s *Denver) addActions() {
 	checkVersion := checkversion.NewCheckVersion(&s.vMProvider, s.updater, s.printer, s.notify)
+	start := actions.NewStart(s.ctx, &s.vMProvider, &s.config.Config.Starttimeout, s.printer, checkVersion)
+	stop := actions.NewStop(s.ctx, &s.vMProvider, &s.config.Config.Stoptimeout, s.printer)
 
 	s.availableActions = append(
 		s.availableActions,
 		actions.NewInit(&s.vMProvider, s.printer, checkVersion),
 		actions.NewSSH(s.ssh, &s.vMProvider, s.printer),
-		actions.NewStart(s.ctx, &s.vMProvider, s.printer, checkVersion),
-		actions.NewStop(s.ctx, &s.vMProvider, s.printer),
-		actions.NewStatus(&s.vMProvider, s.printer),
-		actions.NewTerm(s.workingDirectory, &s.ssh.User, &s.ssh.IP, &s.config.UserInfo.Terminal, &s.config.UserInfo.TerminalArguments, &s.vMProvider, s.printer),
+		start,
+		stop,
+		actions.NewRestart(stop, start),
+		actions.NewSuspend(s.ctx, &s.vMProvider, s.printer),
+		actions.NewResume(&s.vMProvider, start),
+		actions.NewStatus(s.ctx, &s.vMProvider, s.printer),
+		actions.NewTerm(s.ctx, s.workingDirectory, &s.ssh.User, &s.ssh.IP, &s.ssh.Port, &s.config.UserInfo.Terminal, &s.config.UserInfo.TerminalArguments, &s.vMProvider, s.printer),
 		checkVersion,
 		unregister.NewUnregister(&s.vMProvider, s.printer),
+		instances.NewInstances(s.config, &instanceName, s.printer),
+		snapshot.NewSnapshot(&s.vMProvider, s.printer),
+		resize.NewResize(&s.vMProvider, s.printer),
+		disk.NewDisk(&s.vMProvider, s.printer),
+		export.NewExport(s.workingDirectory, s.config, &instanceName, &s.vMProvider, s.printer),
+		export.NewImport(s.workingDirectory, s.config, &instanceName, &s.vMProvider, s.printer),
+		port.NewPort(&s.vMProvider, s.saveInstancePorts, s.printer),
+		share.NewMount(&s.mounter, &s.vMProvider, s.printer),
+		share.NewUmount(&s.mounter, &s.vMProvider, s.printer),
+		network.NewNetwork(&s.vMProvider, s.printer),
+		info.NewInfo(&s.vMProvider, s.printer),
 	)
 }
@@ -0,0 +1,40 @@
+package root
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingMounter struct {
+	failures int
+	umounts  []bool
+}
+
+func (m *testingMounter) Mountpoint() string       { return "/home/jdoe/Projects" }
+func (m *testingMounter) IsMounted() (bool, error) { return true, nil }
+func (m *testingMounter) Mount() error             { return nil }
+func (m *testingMounter) Umount(force bool) error {
+	m.umounts = append(m.umounts, force)
+	if len(m.umounts) <= m.failures {
+		return errors.New("umount: /home/jdoe/Projects: target is busy")
+	}
+	return nil
+}
+
+func TestBusyShareIsForciblyUnmounted(t *testing.T) {
+	assert := assert.New(t)
+	mounter := &testingMounter{failures: 1}
+
+	umountShare(mounter)
+	assert.Equal([]bool{false, true}, mounter.umounts)
+}
+
+func TestShareLeftBehindIsOnlyLogged(t *testing.T) {
+	assert := assert.New(t)
+	mounter := &testingMounter{failures: 2}
+
+	umountShare(mounter)
+	assert.Equal([]bool{false, true}, mounter.umounts)
+}
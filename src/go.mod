@@ -23,6 +23,7 @@ require (
 	github.com/onsi/gomega v1.7.0 // indirect
 	github.com/pierrec/lz4 v2.3.0+incompatible // indirect
 	github.com/spf13/cobra v0.0.5
+	github.com/spf13/pflag v1.0.3
 	github.com/spf13/viper v1.4.0
 	github.com/stretchr/testify v1.2.2
 	github.com/xi2/xz v0.0.0-20171230120015-48954b6210f8 // indirect
@@ -31,4 +32,6 @@ require (
 	golang.org/x/tools v0.0.0-20200207224406-61798d64f025
 	gopkg.in/airbrake/gobrake.v2 v2.0.9 // indirect
 	gopkg.in/gemnasium/logrus-airbrake-hook.v2 v2.1.2 // indirect
+	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v3 v3.0.0-20190709130402-674ba3eaed22
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"denver/cmd"
 	"denver/cmd/root"
+	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -29,7 +30,10 @@ func main() {
 
 	go func() {
 		<-sigs
+		// The running command is waited for, so that the VM is not left half changed
+		fmt.Fprintln(os.Stderr, "Interrupted, waiting for the running command to end, interrupt again to exit now")
 		cancel()
+		<-sigs
 		os.Exit(1)
 	}()
 
@@ -0,0 +1,279 @@
+package archive
+
+import (
+	"denver/pkg/providers"
+	"denver/pkg/providers/virtualbox"
+	"denver/pkg/util"
+	"denver/pkg/util/compressor"
+	"denver/structs"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FormatVersion is bumped on every incompatible change of the archive layout
+const FormatVersion = 1
+
+const (
+	manifestFile = "export.json"
+	configFile   = "config.yml"
+)
+
+// Archive exports and imports an instance as a single portable file
+type Archive struct {
+	workingDirectory string
+	config           *structs.Denver
+	instance         *structs.InstanceConf
+	provider         providers.Exportable
+	compressor       *compressor.MultiCompressor
+}
+
+// Manifest describes the content of an archive
+type Manifest struct {
+	Version    int                 `json:"version"`
+	Date       time.Time           `json:"date"`
+	Instance   string              `json:"instance"`
+	Hypervisor string              `json:"hypervisor"`
+	Channel    string              `json:"channel"`
+	RBI        virtualbox.Manifest `json:"rbi"`
+	Userdata   string              `json:"userdata"`
+	Checksums  map[string]string   `json:"checksums"`
+}
+
+// NewArchive returns a pointer to Archive
+func NewArchive(
+	workingDirectory string,
+	config *structs.Denver,
+	instance *structs.InstanceConf,
+	provider providers.Exportable,
+	compressor *compressor.MultiCompressor,
+) *Archive {
+	return &Archive{
+		workingDirectory: workingDirectory,
+		config:           config,
+		instance:         instance,
+		provider:         provider,
+		compressor:       compressor,
+	}
+}
+
+// Export writes the userdata disk, the RBI reference and the instance configuration to file
+func (a *Archive) Export(file string) (err error) {
+	userData := a.provider.UserDataPath()
+	if exists, err := util.Exists(userData); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("userdata disk %s not found", userData)
+	}
+
+	rbi, err := a.provider.GetRBIManifest()
+	if err != nil {
+		return
+	}
+
+	dir, err := a.stagingDirectory("export")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	config := filepath.Join(dir, configFile)
+	if err = a.writeConfig(config); err != nil {
+		return
+	}
+
+	manifest := Manifest{
+		Version:    FormatVersion,
+		Date:       time.Now(),
+		Instance:   a.instance.Name,
+		Hypervisor: a.hypervisor(),
+		Channel:    a.config.Config.Channel,
+		RBI:        rbi,
+		Userdata:   filepath.Base(userData),
+		Checksums:  map[string]string{},
+	}
+
+	log.Println("Computing checksums...")
+	for _, f := range []string{config, userData} {
+		if manifest.Checksums[filepath.Base(f)], err = util.FileChecksum(f); err != nil {
+			return
+		}
+	}
+
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return
+	}
+	manifestPath := filepath.Join(dir, manifestFile)
+	if err = ioutil.WriteFile(manifestPath, body, 0644); err != nil {
+		return
+	}
+
+	log.Printf("Compressing to %s...", file)
+	return a.compressor.Compress([]string{manifestPath, config, userData}, file)
+}
+
+// Import restores the userdata disk of an archive into the instance, which must not be initialized
+func (a *Archive) Import(file string) (manifest Manifest, err error) {
+	userData := a.provider.UserDataPath()
+	if exists, err := util.Exists(userData); err != nil {
+		return manifest, err
+	} else if exists {
+		return manifest, fmt.Errorf("userdata disk %s already exists, move it away before importing", userData)
+	}
+
+	dir, err := a.stagingDirectory("import")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	log.Printf("Decompressing %s...", file)
+	if err = a.compressor.Decompress(file, dir, 0); err != nil {
+		return
+	}
+
+	if manifest, err = a.readManifest(filepath.Join(dir, manifestFile)); err != nil {
+		return
+	}
+
+	log.Println("Validating checksums...")
+	for name, expected := range manifest.Checksums {
+		checksum, err := util.FileChecksum(filepath.Join(dir, name))
+		if err != nil {
+			return manifest, err
+		}
+		if checksum != expected {
+			return manifest, fmt.Errorf("checksum mismatch for %s, the archive is corrupted", name)
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(userData), os.ModePerm); err != nil {
+		return
+	}
+	if err = move(filepath.Join(dir, manifest.Userdata), userData); err != nil {
+		return
+	}
+
+	// The exported configuration is kept for reference, config.yml is never rewritten
+	imported := filepath.Join(a.workingDirectory, a.instance.Store, fmt.Sprintf("imported-%s", configFile))
+	if err = move(filepath.Join(dir, configFile), imported); err != nil {
+		return
+	}
+	log.Printf("Exported configuration of %s saved to %s", manifest.Instance, imported)
+
+	return
+}
+
+func (a *Archive) readManifest(path string) (manifest Manifest, err error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return manifest, fmt.Errorf("invalid archive, %s is missing", manifestFile)
+	}
+
+	if err = json.Unmarshal(body, &manifest); err != nil {
+		return
+	}
+
+	if manifest.Version != FormatVersion {
+		return manifest, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+	if manifest.Hypervisor != a.hypervisor() {
+		return manifest, fmt.Errorf("archive has been exported from a %s instance, %s can't use it", manifest.Hypervisor, a.hypervisor())
+	}
+	if manifest.Channel != a.config.Config.Channel {
+		return manifest, fmt.Errorf("archive has been exported from the %s channel, set config.channel accordingly", manifest.Channel)
+	}
+	// The names come from the archive, they must not lead out of the staging directory
+	if !a.isUserdataName(manifest.Userdata) {
+		return manifest, fmt.Errorf("invalid archive, %q is not a userdata disk", manifest.Userdata)
+	}
+	for name := range manifest.Checksums {
+		if name != configFile && name != manifest.Userdata {
+			return manifest, fmt.Errorf("invalid archive, unexpected file %q", name)
+		}
+	}
+	for _, name := range []string{configFile, manifest.Userdata} {
+		if _, ok := manifest.Checksums[name]; !ok {
+			return manifest, fmt.Errorf("invalid archive, no checksum for %s", name)
+		}
+	}
+
+	return
+}
+
+// isUserdataName tells whether name is a file of the archive with the extension of the disks of the provider
+func (a *Archive) isUserdataName(name string) bool {
+	if name == "" || filepath.Base(name) != name || name == manifestFile || name == configFile {
+		return false
+	}
+
+	return filepath.Ext(name) == filepath.Ext(a.provider.UserDataPath())
+}
+
+func (a *Archive) writeConfig(path string) (err error) {
+	provider := a.config.Providers[a.instance.Provider]
+	exported := map[string]interface{}{
+		"config": map[string]string{
+			"channel": a.config.Config.Channel,
+			"rbiurl":  a.config.Config.RBIURL,
+		},
+		"instance": map[string]interface{}{
+			"name":         a.instance.Name,
+			"provider":     a.instance.Provider,
+			"vmem":         a.instance.Vmem,
+			"vcpu":         a.instance.Vcpu,
+			"localip":      a.instance.Localip,
+			"userdatasize": a.instance.Userdatasize,
+			"sshuser":      a.instance.Sshuser,
+			"sshport":      a.instance.Sshport,
+			"ports":        a.instance.Ports,
+		},
+		"providers": map[string]interface{}{
+			a.instance.Provider: map[string]string{
+				"name":       provider.Name,
+				"location":   provider.Location,
+				"hypervisor": provider.Hypervisor,
+			},
+		},
+	}
+
+	body, err := yaml.Marshal(exported)
+	if err != nil {
+		return
+	}
+
+	return ioutil.WriteFile(path, body, 0644)
+}
+
+func (a *Archive) hypervisor() string {
+	return a.config.Providers[a.instance.Provider].Hypervisor
+}
+
+// Staging files are kept next to the disks so that they can be moved without a copy
+func (a *Archive) stagingDirectory(prefix string) (string, error) {
+	store := filepath.Join(a.workingDirectory, a.instance.Store)
+	if err := os.MkdirAll(store, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	return ioutil.TempDir(store, prefix)
+}
+
+func move(origin, destination string) error {
+	if err := os.Rename(origin, destination); err == nil {
+		return nil
+	}
+
+	return util.Copy(origin, destination)
+}
@@ -0,0 +1,137 @@
+package archive
+
+import (
+	"denver/pkg/providers/virtualbox"
+	"denver/pkg/util/compressor"
+	"denver/structs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeExportable struct {
+	userData string
+}
+
+func (f *fakeExportable) UserDataPath() string { return f.userData }
+func (f *fakeExportable) GetRBIManifest() (virtualbox.Manifest, error) {
+	return virtualbox.Manifest{Version: "1.2.0"}, nil
+}
+
+func getArchive(dir string, channel string) *Archive {
+	config := structs.NewDenverConfig()
+	config.Config.Channel = channel
+	config.Providers = map[string]structs.Provider{
+		"local-vb": {Name: "local-vb", Hypervisor: "virtualbox"},
+	}
+	instance := &structs.InstanceConf{Name: "denver", Provider: "local-vb", Store: "store"}
+
+	return NewArchive(
+		dir,
+		config,
+		instance,
+		&fakeExportable{userData: filepath.Join(dir, "store", "userdata.vdi")},
+		compressor.NewMultiCompressor(),
+	)
+}
+
+func TestExportedInstanceCanBeImported(t *testing.T) {
+	assert := assert.New(t)
+
+	origin, err := ioutil.TempDir("", "export")
+	assert.NoError(err)
+	defer func() {
+		_ = os.RemoveAll(origin)
+	}()
+	destination, err := ioutil.TempDir("", "import")
+	assert.NoError(err)
+	defer func() {
+		_ = os.RemoveAll(destination)
+	}()
+
+	assert.NoError(os.MkdirAll(filepath.Join(origin, "store"), os.ModePerm))
+	assert.NoError(ioutil.WriteFile(filepath.Join(origin, "store", "userdata.vdi"), []byte("projects"), 0644))
+
+	file := filepath.Join(origin, "denver.tar.bz2")
+	assert.NoError(getArchive(origin, "stable").Export(file))
+
+	manifest, err := getArchive(destination, "stable").Import(file)
+	assert.NoError(err)
+	assert.Equal("denver", manifest.Instance)
+	assert.Equal("1.2.0", manifest.RBI.Version)
+
+	content, err := ioutil.ReadFile(filepath.Join(destination, "store", "userdata.vdi"))
+	assert.NoError(err)
+	assert.Equal("projects", string(content))
+
+	_, err = os.Stat(filepath.Join(destination, "store", "imported-config.yml"))
+	assert.NoError(err)
+
+	_, err = getArchive(destination, "stable").Import(file)
+	assert.Error(err, "existing userdata is never overwritten")
+}
+
+func TestImportRefusesAnotherChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "export")
+	assert.NoError(err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "store"), os.ModePerm))
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "store", "userdata.vdi"), []byte("projects"), 0644))
+
+	file := filepath.Join(dir, "denver.tar.bz2")
+	assert.NoError(getArchive(dir, "beta").Export(file))
+	assert.NoError(os.Remove(filepath.Join(dir, "store", "userdata.vdi")))
+
+	_, err = getArchive(dir, "stable").Import(file)
+	assert.EqualError(err, "archive has been exported from the beta channel, set config.channel accordingly")
+}
+
+func TestImportRefusesNamesLeadingOutOfTheArchive(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "import")
+	assert.NoError(err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	testcases := []struct {
+		manifest string
+		err      string
+	}{
+		{
+			`{"userdata": "../../.ssh/id_rsa.vdi", "checksums": {"config.yml": "", "../../.ssh/id_rsa.vdi": ""}}`,
+			`invalid archive, "../../.ssh/id_rsa.vdi" is not a userdata disk`,
+		},
+		{
+			`{"userdata": "config.yml", "checksums": {"config.yml": ""}}`,
+			`invalid archive, "config.yml" is not a userdata disk`,
+		},
+		{
+			`{"userdata": "userdata.vdi", "checksums": {"config.yml": "", "userdata.vdi": "", "../../etc/passwd": ""}}`,
+			`invalid archive, unexpected file "../../etc/passwd"`,
+		},
+		{
+			`{"userdata": "userdata.vdi", "checksums": {"userdata.vdi": ""}}`,
+			"invalid archive, no checksum for config.yml",
+		},
+	}
+
+	a := getArchive(dir, "stable")
+	for _, testcase := range testcases {
+		path := filepath.Join(dir, manifestFile)
+		body := `{"version": 1, "hypervisor": "virtualbox", "channel": "stable", ` + testcase.manifest[1:]
+		assert.NoError(ioutil.WriteFile(path, []byte(body), 0644))
+
+		_, err := a.readManifest(path)
+		assert.EqualError(err, testcase.err)
+	}
+}
@@ -0,0 +1,32 @@
+package guest
+
+import (
+	"denver/pkg/ssh"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Clock keeps the guest clock in line with the host one
+type Clock struct {
+	ssh *ssh.SSH
+}
+
+// NewClock returns a pointer to Clock
+func NewClock(ssh *ssh.SSH) *Clock {
+	return &Clock{
+		ssh: ssh,
+	}
+}
+
+// Sync sets the guest clock to the host time, the guest clock stands still while the VM is suspended
+func (c *Clock) Sync() (err error) {
+	now := time.Now().UTC()
+	if out, err := c.ssh.Cmd(fmt.Sprintf("sudo date -u -s @%d", now.Unix())); err != nil {
+		return fmt.Errorf("unable to synchronise the guest clock: %s %s", err, out)
+	}
+
+	log.Printf("Guest clock synchronised to %s", now.Format(time.RFC3339))
+
+	return
+}
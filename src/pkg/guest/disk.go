@@ -0,0 +1,72 @@
+package guest
+
+import (
+	"denver/pkg/providers"
+	"denver/pkg/ssh"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// growScript returns the script growing the partition holding the Projects folder, if any, then
+// its filesystem. growpart exits with 1 when there is nothing to grow.
+func growScript(projectsPath string) string {
+	return strings.Join([]string{
+		"set -e",
+		fmt.Sprintf("src=$(findmnt -n -o SOURCE --target %s)", projectsPath),
+		fmt.Sprintf("fstype=$(findmnt -n -o FSTYPE --target %s)", projectsPath),
+		`parent=$(lsblk -n -o PKNAME "$src" | head -n 1)`,
+		`if [ -n "$parent" ]; then sudo growpart "/dev/$parent" "$(cat /sys/class/block/$(basename "$src")/partition)" || [ $? -eq 1 ]; fi`,
+		fmt.Sprintf(`case "$fstype" in xfs) sudo xfs_growfs %s ;; *) sudo resize2fs "$src" ;; esac`, projectsPath),
+	}, "\n")
+}
+
+// Disk grows the guest filesystem once the userdata disk has been enlarged
+type Disk struct {
+	ssh *ssh.SSH
+	// projectsPath is the mount point of the userdata disk content inside the guest
+	projectsPath string
+}
+
+// NewDisk returns a pointer to Disk, user being the SSH user whose home holds the Projects folder
+func NewDisk(ssh *ssh.SSH, user string) *Disk {
+	return &Disk{
+		ssh:          ssh,
+		projectsPath: fmt.Sprintf("/home/%s/Projects", user),
+	}
+}
+
+// GrowFilesystem grows the partition and the filesystem when a grow is pending
+func (d *Disk) GrowFilesystem(grower providers.DiskGrower) (err error) {
+	pending, err := grower.GuestGrowPending()
+	if err != nil || !pending {
+		return
+	}
+
+	before, err := d.filesystemSize()
+	if err != nil {
+		return
+	}
+
+	if out, err := d.ssh.Cmd(growScript(d.projectsPath)); err != nil {
+		return fmt.Errorf("unable to grow the userdata filesystem: %s %s", err, out)
+	}
+
+	after, err := d.filesystemSize()
+	if err != nil {
+		return
+	}
+
+	log.Printf("Userdata filesystem grown from %s to %s", before, after)
+
+	return grower.SetGuestGrowDone()
+}
+
+func (d *Disk) filesystemSize() (size string, err error) {
+	out, err := d.ssh.Cmd(fmt.Sprintf("df -h --output=size %s | tail -n 1", d.projectsPath))
+	if err != nil {
+		return
+	}
+
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,191 @@
+package hooks
+
+import (
+	"context"
+	"denver/pkg/providers"
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultTimeout is the timeout of the hooks which do not set one
+const DefaultTimeout = 60 * time.Second
+
+// Exit status of the guest timeout command when the hook has been killed
+const guestTimeoutStatus = 124
+
+// Guest runs commands in the instance, like ssh.SSH
+type Guest interface {
+	CombinedOutput(command string) (string, error)
+}
+
+// hook is a validated hook of the configuration
+type hook struct {
+	point           providers.LifecyclePoint
+	name            string
+	host            string
+	guest           string
+	timeout         time.Duration
+	continueOnError bool
+}
+
+// Hooks runs the hooks of the configuration on the host and in the guest
+type Hooks struct {
+	executor executor.Executor
+	guest    Guest
+}
+
+// NewHooks returns a pointer to Hooks
+func NewHooks(executor executor.Executor, guest Guest) *Hooks {
+	return &Hooks{
+		executor: executor,
+		guest:    guest,
+	}
+}
+
+// Attach validates the hooks of conf then attaches them to their point of the lifecycle of vmProvider.
+// The guest hooks are left out unless withGuest is set, what they change can't be previewed.
+func (h *Hooks) Attach(conf structs.Hooks, vmProvider providers.VMProvider, withGuest bool) error {
+	hooks, err := newHooks(conf)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if hook.guest != "" && !withGuest {
+			continue
+		}
+
+		hook := hook
+		vmProvider.AddAction(hook.point, func() error {
+			return h.run(hook, vmProvider)
+		})
+	}
+
+	return nil
+}
+
+// newHooks validates the hooks declared in the configuration, they are returned in order
+func newHooks(conf structs.Hooks) (hooks []hook, err error) {
+	points := []struct {
+		point providers.LifecyclePoint
+		confs []structs.Hook
+		// The instance is down at these points, nothing can be run in it
+		guest bool
+	}{
+		{providers.PreStart, conf.PreStart, false},
+		{providers.PostStart, conf.PostStart, true},
+		{providers.PreStop, conf.PreStop, true},
+		{providers.PostStop, conf.PostStop, false},
+		{providers.PreUpdate, conf.PreUpdate, true},
+		{providers.PostUpdate, conf.PostUpdate, false},
+	}
+
+	for _, p := range points {
+		for i, c := range p.confs {
+			name := c.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", i+1)
+			}
+
+			switch {
+			case c.Host == "" && c.Guest == "":
+				return nil, fmt.Errorf("invalid %s hook %s: set either host or guest", p.point, name)
+			case c.Host != "" && c.Guest != "":
+				return nil, fmt.Errorf("invalid %s hook %s: set only one of host and guest", p.point, name)
+			case c.Guest != "" && !p.guest:
+				return nil, fmt.Errorf("invalid %s hook %s: the instance is not running at %s, use host", p.point, name, p.point)
+			case c.Timeout < 0:
+				return nil, fmt.Errorf("invalid %s hook %s: timeout %d is negative", p.point, name, c.Timeout)
+			}
+
+			timeout := DefaultTimeout
+			if c.Timeout > 0 {
+				timeout = time.Duration(c.Timeout) * time.Second
+			}
+
+			hooks = append(hooks, hook{
+				point:           p.point,
+				name:            name,
+				host:            c.Host,
+				guest:           c.Guest,
+				timeout:         timeout,
+				continueOnError: c.ContinueOnError,
+			})
+		}
+	}
+
+	return
+}
+
+// run runs hook, its failure is only logged when it continues on error
+func (h *Hooks) run(hook hook, vmProvider providers.VMProvider) (err error) {
+	if hook.guest != "" && !vmProvider.GetState().OsReady {
+		log.Printf("Skipping the %s hook %s, the instance is not running", hook.point, hook.name)
+		return
+	}
+
+	log.Printf("Running the %s hook %s", hook.point, hook.name)
+	if hook.host != "" {
+		err = h.runHost(hook)
+	} else {
+		err = h.runGuest(hook)
+	}
+	if err == nil {
+		return
+	}
+
+	err = fmt.Errorf("%s hook %s failed: %s", hook.point, hook.name, err)
+	if hook.continueOnError {
+		log.Println(err)
+		return nil
+	}
+
+	return
+}
+
+// runHost runs the hook with the shell of the host, its output is logged as it goes
+func (h *Hooks) runHost(hook hook) error {
+	_, err := h.executor.ExecuteWithOptions(hostShell(hook.host), executor.Options{
+		Timeout:       hook.timeout,
+		Interruptible: true,
+		Stream:        true,
+	})
+	if e, ok := err.(*executor.Error); ok && e.Err == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", hook.timeout)
+	}
+
+	return err
+}
+
+// runGuest runs the hook in the guest, killed by timeout once it is reached, its output is logged once done
+func (h *Hooks) runGuest(hook hook) error {
+	out, err := h.guest.CombinedOutput(fmt.Sprintf("timeout %d sh -c %s",
+		int(hook.timeout/time.Second),
+		executor.ShellQuote(hook.guest),
+	))
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			log.Println(line)
+		}
+	}
+	if e, ok := err.(*ssh.ExitError); ok && e.ExitStatus() == guestTimeoutStatus {
+		return fmt.Errorf("timed out after %s", hook.timeout)
+	}
+
+	return err
+}
+
+func hostShell(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+
+	return []string{"sh", "-c", command}
+}
@@ -0,0 +1,142 @@
+package hooks
+
+import (
+	"context"
+	"denver/pkg/providers"
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingExecutor struct {
+	commands [][]string
+	options  []executor.Options
+	err      error
+}
+
+func (e *testingExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, executor.Options{})
+}
+
+func (e *testingExecutor) ExecuteWithOptions(args []string, options executor.Options) (string, error) {
+	e.commands = append(e.commands, args)
+	e.options = append(e.options, options)
+	return "", e.err
+}
+
+type testingGuest struct {
+	commands []string
+	out      string
+	err      error
+}
+
+func (g *testingGuest) CombinedOutput(command string) (string, error) {
+	g.commands = append(g.commands, command)
+	return g.out, g.err
+}
+
+func TestHooksAreValidated(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		hooks structs.Hooks
+		err   string
+	}{
+		{structs.Hooks{PreStart: []structs.Hook{{Name: "vpn"}}}, "invalid pre-start hook vpn: set either host or guest"},
+		{structs.Hooks{PostStart: []structs.Hook{{Host: "true", Guest: "true"}}}, "invalid post-start hook 1: set only one of host and guest"},
+		{structs.Hooks{PostStop: []structs.Hook{{Name: "dump", Guest: "mysqldump"}}}, "invalid post-stop hook dump: the instance is not running at post-stop, use host"},
+		{structs.Hooks{PreStop: []structs.Hook{{Guest: "true", Timeout: -1}}}, "invalid pre-stop hook 1: timeout -1 is negative"},
+	}
+
+	for _, testcase := range testcases {
+		_, err := newHooks(testcase.hooks)
+		assert.EqualError(err, testcase.err)
+	}
+}
+
+func TestHooksRunInOrderAtTheirPoint(t *testing.T) {
+	assert := assert.New(t)
+	host := &testingExecutor{}
+	guest := &testingGuest{}
+	fake := providers.NewFake(providers.State{Live: true, OsReady: true, AllSystemsReady: true}).
+		Script("Stop", providers.ShutdownTransitions(0)...)
+
+	err := NewHooks(host, guest).Attach(structs.Hooks{
+		PreStop: []structs.Hook{
+			{Name: "dump", Guest: "mysqldump app > 'app.sql'", Timeout: 10},
+			{Name: "notify", Host: "notify-send stopping"},
+		},
+		PostStop: []structs.Hook{
+			{Name: "vpn", Host: "vpn down"},
+		},
+	}, fake, true)
+	assert.NoError(err)
+
+	assert.NoError(fake.Stop())
+	assert.Equal([]string{`timeout 10 sh -c 'mysqldump app > '\''app.sql'\'''`}, guest.commands)
+	assert.Equal([][]string{hostShell("notify-send stopping")}, host.commands)
+	assert.Equal(executor.Options{Timeout: DefaultTimeout, Interruptible: true, Stream: true}, host.options[0])
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.True(fake.StateStore().Wait(ctx, func(state *providers.State) bool { return !state.Live }))
+	assert.Equal([][]string{hostShell("notify-send stopping"), hostShell("vpn down")}, host.commands)
+}
+
+func TestFailingHookStopsTheOperation(t *testing.T) {
+	assert := assert.New(t)
+	host := &testingExecutor{err: fmt.Errorf("exit status 1")}
+	fake := providers.NewFake(providers.State{})
+
+	err := NewHooks(host, &testingGuest{}).Attach(structs.Hooks{
+		PreStart: []structs.Hook{{Name: "vpn", Host: "vpn up"}},
+	}, fake, true)
+	assert.NoError(err)
+
+	assert.EqualError(fake.Start(), "pre-start hook vpn failed: exit status 1")
+	assert.Empty(fake.Calls())
+}
+
+func TestFailingHookCanContinue(t *testing.T) {
+	assert := assert.New(t)
+	host := &testingExecutor{err: fmt.Errorf("exit status 1")}
+	fake := providers.NewFake(providers.State{})
+
+	err := NewHooks(host, &testingGuest{}).Attach(structs.Hooks{
+		PreStart: []structs.Hook{
+			{Name: "vpn", Host: "vpn up", ContinueOnError: true},
+			{Name: "notify", Host: "notify-send starting", ContinueOnError: true},
+		},
+	}, fake, true)
+	assert.NoError(err)
+
+	assert.NoError(fake.Start())
+	assert.Len(host.commands, 2)
+	assert.Equal([]string{"Start"}, fake.Calls())
+}
+
+func TestGuestHooksAreSkipped(t *testing.T) {
+	assert := assert.New(t)
+	guest := &testingGuest{}
+	fake := providers.NewFake(providers.State{}).SetUpToDate(false)
+
+	// Not attached, as in dry-run
+	err := NewHooks(&testingExecutor{}, guest).Attach(structs.Hooks{
+		PostStart: []structs.Hook{{Guest: "docker-compose up -d"}},
+	}, fake, false)
+	assert.NoError(err)
+
+	// Attached, but the instance is not running
+	err = NewHooks(&testingExecutor{}, guest).Attach(structs.Hooks{
+		PreUpdate: []structs.Hook{{Guest: "mysqldump app > app.sql"}},
+	}, fake, true)
+	assert.NoError(err)
+
+	updated, err := fake.Update()
+	assert.NoError(err)
+	assert.True(updated)
+	assert.Empty(guest.commands)
+}
@@ -0,0 +1,17 @@
+// +build !windows
+
+package host
+
+import (
+	"syscall"
+)
+
+// FreeDiskSpace returns the space available to the user on the filesystem of path, in bytes
+func FreeDiskSpace(path string) (free uint64, known bool, err error) {
+	var stat syscall.Statfs_t
+	if err = syscall.Statfs(path, &stat); err != nil {
+		return
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true, nil
+}
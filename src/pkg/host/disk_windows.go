@@ -0,0 +1,22 @@
+package host
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// FreeDiskSpace returns the space available to the user on the volume of path, in bytes
+func FreeDiskSpace(path string) (free uint64, known bool, err error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return
+	}
+
+	if ret, _, callErr := getDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(&free)), 0, 0); ret == 0 {
+		return 0, false, callErr
+	}
+
+	return free, true, nil
+}
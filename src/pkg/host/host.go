@@ -0,0 +1,80 @@
+package host
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Capabilities of the host which can't be checked on every OS are reported as unknown
+// by the OS specific functions, the callers skip the related checks.
+
+var vmStatPageSizeRegexp = regexp.MustCompile(`page size of ([0-9]+) bytes`)
+
+// parseCPUFlags looks for the VT-x or AMD-V flag in /proc/cpuinfo
+func parseCPUFlags(cpuinfo string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(cpuinfo))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "flags") {
+			continue
+		}
+
+		for _, flag := range strings.Fields(line) {
+			if flag == "vmx" || flag == "svm" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// parseMeminfo returns MemAvailable of /proc/meminfo in bytes
+func parseMeminfo(meminfo string) (available uint64, ok bool) {
+	scanner := bufio.NewScanner(strings.NewReader(meminfo))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb * 1024, true
+	}
+
+	return 0, false
+}
+
+// parseVMStat returns the free, inactive and speculative pages of vm_stat in bytes
+func parseVMStat(vmStat string) (available uint64, ok bool) {
+	matches := vmStatPageSizeRegexp.FindStringSubmatch(vmStat)
+	if len(matches) < 2 {
+		return 0, false
+	}
+	pageSize, _ := strconv.ParseUint(matches[1], 10, 64)
+
+	scanner := bufio.NewScanner(strings.NewReader(vmStat))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		switch strings.TrimSpace(parts[0]) {
+		case "Pages free", "Pages inactive", "Pages speculative":
+			pages, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(parts[1]), "."), 10, 64)
+			if err != nil {
+				return 0, false
+			}
+			available += pages * pageSize
+			ok = true
+		}
+	}
+
+	return
+}
@@ -0,0 +1,34 @@
+package host
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// VirtualizationSupported tells whether the CPU exposes VT-x
+func VirtualizationSupported() (supported bool, known bool, err error) {
+	out, err := exec.Command("sysctl", "-n", "machdep.cpu.features").Output()
+	if err != nil {
+		// Not exposed on every Mac, the hypervisor will complain by itself
+		return false, false, nil
+	}
+
+	for _, feature := range strings.Fields(string(out)) {
+		if feature == "VMX" {
+			return true, true, nil
+		}
+	}
+
+	return false, true, nil
+}
+
+// AvailableMemory returns the memory which can be allocated without swapping, in bytes
+func AvailableMemory() (available uint64, known bool, err error) {
+	out, err := exec.Command("vm_stat").Output()
+	if err != nil {
+		return
+	}
+
+	available, known = parseVMStat(string(out))
+	return
+}
@@ -0,0 +1,26 @@
+package host
+
+import (
+	"io/ioutil"
+)
+
+// VirtualizationSupported tells whether the CPU exposes VT-x or AMD-V
+func VirtualizationSupported() (supported bool, known bool, err error) {
+	cpuinfo, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return
+	}
+
+	return parseCPUFlags(string(cpuinfo)), true, nil
+}
+
+// AvailableMemory returns the memory which can be allocated without swapping, in bytes
+func AvailableMemory() (available uint64, known bool, err error) {
+	meminfo, err := ioutil.ReadFile("/proc/meminfo")
+	if err != nil {
+		return
+	}
+
+	available, known = parseMeminfo(string(meminfo))
+	return
+}
@@ -0,0 +1,13 @@
+// +build !linux,!darwin
+
+package host
+
+// VirtualizationSupported is unknown on this OS
+func VirtualizationSupported() (supported bool, known bool, err error) {
+	return
+}
+
+// AvailableMemory is unknown on this OS
+func AvailableMemory() (available uint64, known bool, err error) {
+	return
+}
@@ -0,0 +1,98 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCPUFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(parseCPUFlags("processor\t: 0\nflags\t\t: fpu vme de pse vmx ssse3\n"))
+	assert.True(parseCPUFlags("flags\t\t: fpu svm\n"))
+	assert.False(parseCPUFlags("flags\t\t: fpu vme de pse hypervisor\n"))
+}
+
+func TestParseMeminfo(t *testing.T) {
+	assert := assert.New(t)
+
+	available, ok := parseMeminfo("MemTotal:       16314440 kB\nMemFree:         1024000 kB\nMemAvailable:    8000000 kB\n")
+	assert.True(ok)
+	assert.Equal(uint64(8000000*1024), available)
+
+	_, ok = parseMeminfo("MemTotal:       16314440 kB\n")
+	assert.False(ok)
+}
+
+func TestParseVMStat(t *testing.T) {
+	assert := assert.New(t)
+
+	available, ok := parseVMStat(`Mach Virtual Memory Statistics: (page size of 4096 bytes)
+Pages free:                               100.
+Pages active:                             500.
+Pages inactive:                           200.
+Pages speculative:                         50.
+`)
+	assert.True(ok)
+	assert.Equal(uint64(350*4096), available)
+}
+
+func TestParseIPRoute(t *testing.T) {
+	assert := assert.New(t)
+
+	routes := parseIPRoute(`default via 192.168.1.254 dev wlan0 proto dhcp metric 600
+0.0.0.0/1 via 10.8.0.1 dev tun0
+10.8.0.0/24 dev tun0 proto kernel scope link src 10.8.0.2
+10.10.10.0/24 dev vboxnet0 proto kernel scope link src 10.10.10.1
+172.17.0.0/16 dev docker0 proto kernel scope link src 172.17.0.1 linkdown
+unreachable 10.99.0.0/16
+192.168.1.42 via 192.168.1.254 dev wlan0
+`)
+	var destinations []string
+	for _, route := range routes {
+		destinations = append(destinations, route.String())
+	}
+	assert.Equal([]string{"10.8.0.0/24", "10.10.10.0/24", "172.17.0.0/16", "10.99.0.0/16", "192.168.1.42/32"}, destinations)
+}
+
+func TestParseNetstatRoutes(t *testing.T) {
+	assert := assert.New(t)
+
+	routes := parseNetstatRoutes(`Routing tables
+
+Internet:
+Destination        Gateway            Flags        Netif Expire
+default            192.168.1.254      UGScg          en0
+10.8/16            10.8.0.1           UGSc         utun3
+127                127.0.0.1          UCS            lo0
+192.168.1          link#6             UCS            en0      !
+192.168.1.42/32    link#6             UCS            en0      !
+`)
+	var destinations []string
+	for _, route := range routes {
+		destinations = append(destinations, route.String())
+	}
+	assert.Equal([]string{"10.8.0.0/16", "127.0.0.0/8", "192.168.1.0/24", "192.168.1.42/32"}, destinations)
+}
+
+func TestParseRoutePrint(t *testing.T) {
+	assert := assert.New(t)
+
+	routes := parseRoutePrint(`IPv4 Route Table
+===========================================================================
+Active Routes:
+Network Destination        Netmask          Gateway       Interface  Metric
+          0.0.0.0          0.0.0.0    192.168.1.254    192.168.1.42     35
+         10.8.0.0      255.255.0.0         10.8.0.1        10.8.0.2      1
+      192.168.1.0    255.255.255.0         On-link     192.168.1.42    291
+===========================================================================
+Persistent Routes:
+  None
+`)
+	var destinations []string
+	for _, route := range routes {
+		destinations = append(destinations, route.String())
+	}
+	assert.Equal([]string{"10.8.0.0/16", "192.168.1.0/24"}, destinations)
+}
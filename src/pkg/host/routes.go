@@ -0,0 +1,110 @@
+package host
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Routes broader than this prefix are catch-all ones, default routes or the halves of the
+// internet a VPN redirects, they don't tell where the host-only networks can go
+const minRoutePrefix = 8
+
+// parseIPRoute reads the destinations of `ip -4 route show`
+func parseIPRoute(out string) (routes []*net.IPNet) {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		destination := fields[0]
+		switch destination {
+		case "unicast", "local", "broadcast", "blackhole", "unreachable", "prohibit", "throw":
+			if len(fields) < 2 {
+				continue
+			}
+			destination = fields[1]
+		}
+
+		if !strings.Contains(destination, "/") {
+			destination += "/32"
+		}
+		if _, network, err := net.ParseCIDR(destination); err == nil {
+			routes = appendRoute(routes, network)
+		}
+	}
+
+	return
+}
+
+// parseNetstatRoutes reads the destinations of `netstat -rn -f inet`, where the trailing zero
+// octets are left out: 10.8/16, 192.168.1 for 192.168.1.0/24 or 127 for 127.0.0.0/8
+func parseNetstatRoutes(out string) (routes []*net.IPNet) {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		destination, prefix := fields[0], -1
+		if i := strings.Index(destination, "/"); i >= 0 {
+			var err error
+			if prefix, err = strconv.Atoi(destination[i+1:]); err != nil {
+				continue
+			}
+			destination = destination[:i]
+		}
+
+		octets := strings.Split(destination, ".")
+		if len(octets) > 4 {
+			continue
+		}
+		if prefix < 0 {
+			prefix = len(octets) * 8
+		}
+		for len(octets) < 4 {
+			octets = append(octets, "0")
+		}
+
+		ip := net.ParseIP(strings.Join(octets, ".")).To4()
+		if ip == nil || prefix > 32 {
+			continue
+		}
+		mask := net.CIDRMask(prefix, 32)
+		routes = appendRoute(routes, &net.IPNet{IP: ip.Mask(mask), Mask: mask})
+	}
+
+	return
+}
+
+// parseRoutePrint reads the IPv4 destinations and netmasks of `route print -4`
+func parseRoutePrint(out string) (routes []*net.IPNet) {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		ip := net.ParseIP(fields[0]).To4()
+		mask := net.ParseIP(fields[1]).To4()
+		if ip == nil || mask == nil {
+			continue
+		}
+		routes = appendRoute(routes, &net.IPNet{IP: ip.Mask(net.IPMask(mask)), Mask: net.IPMask(mask)})
+	}
+
+	return
+}
+
+func appendRoute(routes []*net.IPNet, route *net.IPNet) []*net.IPNet {
+	if prefix, _ := route.Mask.Size(); prefix < minRoutePrefix {
+		return routes
+	}
+
+	return append(routes, route)
+}
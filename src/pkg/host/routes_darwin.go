@@ -0,0 +1,16 @@
+package host
+
+import (
+	"net"
+	"os/exec"
+)
+
+// Routes returns the destinations of the IPv4 routing table, default routes left out
+func Routes() (routes []*net.IPNet, err error) {
+	out, err := exec.Command("netstat", "-rn", "-f", "inet").Output()
+	if err != nil {
+		return
+	}
+
+	return parseNetstatRoutes(string(out)), nil
+}
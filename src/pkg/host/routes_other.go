@@ -0,0 +1,12 @@
+// +build !linux,!darwin,!windows
+
+package host
+
+import (
+	"net"
+)
+
+// Routes are unknown on this OS
+func Routes() (routes []*net.IPNet, err error) {
+	return
+}
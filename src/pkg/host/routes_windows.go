@@ -0,0 +1,16 @@
+package host
+
+import (
+	"net"
+	"os/exec"
+)
+
+// Routes returns the destinations of the IPv4 routing table, default routes left out
+func Routes() (routes []*net.IPNet, err error) {
+	out, err := exec.Command("route", "print", "-4").Output()
+	if err != nil {
+		return
+	}
+
+	return parseRoutePrint(string(out)), nil
+}
@@ -0,0 +1,170 @@
+package mount
+
+import (
+	"bufio"
+	"denver/pkg/util/executor"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sudo prompts for the password on the terminal, the commands must stay in its reach
+var commandOptions = executor.Options{Interruptible: true}
+
+// Mounter mounts the Projects share of an instance on the host
+type Mounter interface {
+	Mountpoint() string
+	IsMounted() (bool, error)
+	Mount() error
+	Umount(force bool) error
+}
+
+// DefaultMounter mounts the Projects share of an instance on the host, through NFS on
+// Mac/Linux and Samba on Windows
+type DefaultMounter struct {
+	ip         string
+	user       string
+	mountpoint string
+	goos       string
+	executor   executor.Executor
+}
+
+// NewDefaultMounter returns a pointer to DefaultMounter, the default mountpoint of the OS is used when mountpoint is empty
+func NewDefaultMounter(ip, user, mountpoint, instanceName string, executor executor.Executor) *DefaultMounter {
+	if mountpoint == "" {
+		mountpoint = DefaultMountpoint(runtime.GOOS, instanceName)
+	}
+
+	return &DefaultMounter{
+		ip:         ip,
+		user:       user,
+		mountpoint: mountpoint,
+		goos:       runtime.GOOS,
+		executor:   executor,
+	}
+}
+
+// DefaultMountpoint returns the mountpoint documented for each OS
+func DefaultMountpoint(goos, instanceName string) string {
+	switch goos {
+	case "windows":
+		return "Z:"
+	case "darwin":
+		return filepath.Join("/private", instanceName)
+	}
+
+	return filepath.Join("/media", os.Getenv("USER"), instanceName)
+}
+
+// IsReadOnlyCommand tells whether a command of the mounter only lists the mounts
+func IsReadOnlyCommand(args []string) bool {
+	return strings.Join(args, " ") == "mount" || strings.Join(args, " ") == "net use"
+}
+
+// Mountpoint returns the host path of the share
+func (m *DefaultMounter) Mountpoint() string {
+	return m.mountpoint
+}
+
+// IsMounted checks whether something is mounted on the mountpoint
+func (m *DefaultMounter) IsMounted() (bool, error) {
+	cmd := []string{"mount"}
+	if m.goos == "windows" {
+		cmd = []string{"net", "use"}
+	}
+
+	out, err := m.executor.ExecuteWithOptions(cmd, commandOptions)
+	if err != nil {
+		return false, err
+	}
+
+	return m.isMounted(out), nil
+}
+
+// Mount mounts the share, the mountpoint is created if needed
+func (m *DefaultMounter) Mount() (err error) {
+	if m.goos != "windows" {
+		cmd := []string{"sudo", "mkdir", "-p", m.mountpoint}
+		if _, err = m.executor.ExecuteWithOptions(cmd, commandOptions); err != nil {
+			return
+		}
+	}
+
+	_, err = m.executor.ExecuteWithOptions(m.mountCommand(), commandOptions)
+	return
+}
+
+// Umount unmounts the share, force is needed when the instance is not reachable anymore
+func (m *DefaultMounter) Umount(force bool) (err error) {
+	_, err = m.executor.ExecuteWithOptions(m.umountCommand(force), commandOptions)
+	return
+}
+
+func (m *DefaultMounter) mountCommand() []string {
+	switch m.goos {
+	case "windows":
+		return []string{
+			"net", "use", m.mountpoint,
+			fmt.Sprintf(`\\%s\Projects`, m.ip),
+		}
+	case "darwin":
+		return []string{
+			"sudo", "mount", "-t", "nfs",
+			"-o", "resvport,rw,noatime,rsize=32768,wsize=32768,timeo=10",
+			m.source(), m.mountpoint,
+		}
+	}
+
+	return []string{
+		"sudo", "mount", "-t", "nfs",
+		"-o", "rw,noatime,soft,timeo=10",
+		m.source(), m.mountpoint,
+	}
+}
+
+func (m *DefaultMounter) umountCommand(force bool) []string {
+	switch m.goos {
+	case "windows":
+		return []string{"net", "use", m.mountpoint, "/delete", "/y"}
+	case "darwin":
+		if force {
+			return []string{"sudo", "umount", "-f", m.mountpoint}
+		}
+		return []string{"sudo", "umount", m.mountpoint}
+	}
+
+	if force {
+		// A lazy unmount does not hang on an unreachable NFS server
+		return []string{"sudo", "umount", "-f", "-l", m.mountpoint}
+	}
+	return []string{"sudo", "umount", m.mountpoint}
+}
+
+func (m *DefaultMounter) source() string {
+	return fmt.Sprintf("%s:/home/%s/Projects", m.ip, m.user)
+}
+
+// isMounted looks for the mountpoint in the output of `mount` or `net use`
+func (m *DefaultMounter) isMounted(out string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m.goos == "windows" {
+			fields := strings.Fields(line)
+			for i, field := range fields {
+				if strings.EqualFold(field, m.mountpoint) && i+1 < len(fields) {
+					return true
+				}
+			}
+			continue
+		}
+
+		if strings.Contains(line, fmt.Sprintf(" on %s ", m.mountpoint)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package mount
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMount(goos, mountpoint string) *DefaultMounter {
+	return &DefaultMounter{
+		ip:         "10.10.10.10",
+		user:       "ldevuser",
+		mountpoint: mountpoint,
+		goos:       goos,
+	}
+}
+
+func TestMountCommandDependsOnTheOS(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(
+		[]string{"sudo", "mount", "-t", "nfs", "-o", "rw,noatime,soft,timeo=10", "10.10.10.10:/home/ldevuser/Projects", "/media/jdoe/denver"},
+		getMount("linux", "/media/jdoe/denver").mountCommand(),
+	)
+	assert.Equal(
+		[]string{"sudo", "mount", "-t", "nfs", "-o", "resvport,rw,noatime,rsize=32768,wsize=32768,timeo=10", "10.10.10.10:/home/ldevuser/Projects", "/private/denver"},
+		getMount("darwin", "/private/denver").mountCommand(),
+	)
+	assert.Equal(
+		[]string{"net", "use", "Z:", `\\10.10.10.10\Projects`},
+		getMount("windows", "Z:").mountCommand(),
+	)
+}
+
+func TestForcedUmountIsLazyOnLinux(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"sudo", "umount", "-f", "-l", "/media/jdoe/denver"}, getMount("linux", "/media/jdoe/denver").umountCommand(true))
+	assert.Equal([]string{"sudo", "umount", "/media/jdoe/denver"}, getMount("linux", "/media/jdoe/denver").umountCommand(false))
+}
+
+func TestIsMounted(t *testing.T) {
+	assert := assert.New(t)
+
+	linux := `/dev/sda1 on / type ext4 (rw,relatime)
+10.10.10.10:/home/ldevuser/Projects on /media/jdoe/denver type nfs4 (rw,noatime)
+`
+	assert.True(getMount("linux", "/media/jdoe/denver").isMounted(linux))
+	assert.False(getMount("linux", "/media/jdoe/den").isMounted(linux))
+
+	darwin := `/dev/disk1s1 on / (apfs, local, journaled)
+10.10.10.10:/home/ldevuser/Projects on /private/denver (nfs, asynchronous)
+`
+	assert.True(getMount("darwin", "/private/denver").isMounted(darwin))
+
+	windows := `Status       Local     Remote                    Network
+-------------------------------------------------------------------------------
+OK           Z:        \\10.10.10.10\Projects    Microsoft Windows Network
+`
+	assert.True(getMount("windows", "z:").isMounted(windows))
+	assert.False(getMount("windows", "Y:").isMounted(windows))
+}
+
+func TestDefaultMountpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Z:", DefaultMountpoint("windows", "denver"))
+	assert.Equal("/private/denver", DefaultMountpoint("darwin", "denver"))
+}
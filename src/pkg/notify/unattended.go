@@ -0,0 +1,24 @@
+package notify
+
+// Unattended answers no without asking while unattended is set, when the output is read by scripts
+type Unattended struct {
+	Notify
+	unattended *bool
+}
+
+// NewUnattended returns a pointer to Unattended
+func NewUnattended(notify Notify, unattended *bool) *Unattended {
+	return &Unattended{
+		Notify:     notify,
+		unattended: unattended,
+	}
+}
+
+// AskQuestion asks the question unless unattended
+func (u *Unattended) AskQuestion(question string) (answer bool) {
+	if *u.unattended {
+		return false
+	}
+
+	return u.Notify.AskQuestion(question)
+}
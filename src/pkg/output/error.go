@@ -0,0 +1,65 @@
+package output
+
+import (
+	"context"
+	"denver/pkg/util/executor"
+	"fmt"
+)
+
+// Codes of the errors, they are part of the documented output and must not change
+const (
+	// CodeFailed is the code of the errors without a more specific one
+	CodeFailed = "failed"
+	// CodeCommandFailed is used when a command run on the host, VBoxManage for instance, fails
+	CodeCommandFailed = "command-failed"
+	// CodeInterrupted is used when the command has been interrupted
+	CodeInterrupted = "interrupted"
+	// CodeUnsupported is used when the VM provider or the system does not support the command
+	CodeUnsupported = "unsupported"
+	// CodeNotReady is used when the command needs the VM to be ready
+	CodeNotReady = "not-ready"
+	// CodeInvalidState is used when the VM is not in the state required by the command, started for instance
+	CodeInvalidState = "invalid-state"
+	// CodeInvalidArgument is used when an argument of the command is not valid
+	CodeInvalidArgument = "invalid-argument"
+)
+
+// Error is an error with a code, so that scripts don't depend on messages
+type Error struct {
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+	// ExitCode is set when a command run on the host failed
+	ExitCode *int `json:"exitCode,omitempty" yaml:"exitCode,omitempty"`
+}
+
+// Errorf returns an Error with code and a formatted message
+func Errorf(code, format string, a ...interface{}) error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorOf returns err as an Error, its code is guessed from its type when it has none
+func ErrorOf(err error) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case *executor.Error:
+		if executor.IsCanceled(e) {
+			return &Error{Code: CodeInterrupted, Message: e.Error()}
+		}
+		exitCode := e.ExitCode
+		return &Error{Code: CodeCommandFailed, Message: e.Error(), ExitCode: &exitCode}
+	}
+
+	if err == context.Canceled {
+		return &Error{Code: CodeInterrupted, Message: err.Error()}
+	}
+
+	return &Error{Code: CodeFailed, Message: err.Error()}
+}
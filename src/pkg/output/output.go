@@ -0,0 +1,241 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Format of the output of the commands
+type Format string
+
+const (
+	// Text is the output for humans, with colors
+	Text Format = "text"
+	// JSON writes a single JSON document once the command is done
+	JSON Format = "json"
+	// YAML writes a single YAML document once the command is done
+	YAML Format = "yaml"
+)
+
+var (
+	ansiRegexp  = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	levelRegexp = regexp.MustCompile(`^\[(OK|KO|INFO|SKIP|DRY-RUN)\] ?`)
+)
+
+// ParseFormat returns the Format named format
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case Text, JSON, YAML:
+		return Format(format), nil
+	}
+
+	return "", fmt.Errorf("unknown output %s, use text, json or yaml", format)
+}
+
+// Message is a line printed by a command, Level is ok, ko, info, skip or dry-run, empty for the other lines
+type Message struct {
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+	Text  string `json:"text" yaml:"text"`
+}
+
+// Result is the document describing a command, written once it is done in the machine formats
+type Result struct {
+	Command  string      `json:"command" yaml:"command"`
+	OK       bool        `json:"ok" yaml:"ok"`
+	Version  string      `json:"version" yaml:"version"`
+	State    interface{} `json:"state,omitempty" yaml:"state,omitempty"`
+	Data     interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	Messages []Message   `json:"messages" yaml:"messages"`
+	Error    *Error      `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Printer is the writer of the printer of the actions. In text format the lines are written as is,
+// in the machine formats they are collected, without colors, in the Result written by Flush.
+type Printer struct {
+	mutex   sync.Mutex
+	format  Format
+	out     io.Writer
+	partial []byte
+	result  Result
+	// streamed is set once data has been streamed, the Result is then written the same way
+	streamed bool
+}
+
+// NewPrinter returns a pointer to Printer writing to out
+func NewPrinter(format Format, out io.Writer) *Printer {
+	return &Printer{
+		format: format,
+		out:    out,
+		result: Result{Messages: []Message{}},
+	}
+}
+
+// Machine tells whether the output is read by scripts
+func (p *Printer) Machine() bool {
+	return p.format != Text
+}
+
+// Write prints the lines of the actions
+func (p *Printer) Write(b []byte) (int, error) {
+	if !p.Machine() {
+		return p.out.Write(b)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		p.addMessage(string(p.partial[:i]))
+		p.partial = p.partial[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// SetData sets the data of the Result
+func (p *Printer) SetData(data interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.result.Data = data
+}
+
+// SetState sets the state of the VM in the Result
+func (p *Printer) SetState(state interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.result.State = state
+}
+
+// Stream writes data right away, as a JSON line or a YAML document, for the commands which never end
+func (p *Printer) Stream(data interface{}) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.streamed = p.Machine()
+	switch p.format {
+	case JSON:
+		body, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.out, "%s\n", body)
+		return err
+	case YAML:
+		body, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.out, "---\n%s", body)
+		return err
+	}
+
+	return nil
+}
+
+// Flush writes the Result of command in the machine formats, err is the error the command ended with.
+// After streamed data it is written as one more JSON line or YAML document
+func (p *Printer) Flush(command, version string, err error) error {
+	if !p.Machine() {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.partial) > 0 {
+		p.addMessage(string(p.partial))
+		p.partial = nil
+	}
+	p.result.Command = command
+	p.result.Version = version
+	p.result.OK = err == nil
+	if err != nil {
+		p.result.Error = ErrorOf(err)
+	}
+
+	var body []byte
+	switch {
+	case p.format == JSON && p.streamed:
+		if body, err = json.Marshal(p.result); err != nil {
+			return err
+		}
+		body = append(body, '\n')
+	case p.format == JSON:
+		if body, err = json.MarshalIndent(p.result, "", "  "); err != nil {
+			return err
+		}
+		body = append(body, '\n')
+	default:
+		if body, err = yaml.Marshal(p.result); err != nil {
+			return err
+		}
+		if p.streamed {
+			body = append([]byte("---\n"), body...)
+		}
+	}
+
+	_, err = p.out.Write(body)
+	return err
+}
+
+func (p *Printer) addMessage(line string) {
+	line = strings.TrimSpace(ansiRegexp.ReplaceAllString(line, ""))
+	if line == "" {
+		return
+	}
+
+	message := Message{Text: line}
+	if matches := levelRegexp.FindStringSubmatch(line); matches != nil {
+		message.Level = strings.ToLower(matches[1])
+		message.Text = line[len(matches[0]):]
+	}
+	p.result.Messages = append(p.result.Messages, message)
+}
+
+// SetData gives data to the Printer the logger writes to, it returns false in text format so that
+// the caller prints it for humans instead
+func SetData(logger *log.Logger, data interface{}) bool {
+	printer := printerOf(logger)
+	if printer == nil {
+		return false
+	}
+
+	printer.SetData(data)
+	return true
+}
+
+// Stream writes data right away to the Printer the logger writes to, it returns false in text format
+func Stream(logger *log.Logger, data interface{}) (bool, error) {
+	printer := printerOf(logger)
+	if printer == nil {
+		return false, nil
+	}
+
+	return true, printer.Stream(data)
+}
+
+func printerOf(logger *log.Logger) *Printer {
+	if logger == nil {
+		return nil
+	}
+
+	printer, ok := logger.Writer().(*Printer)
+	if !ok || !printer.Machine() {
+		return nil
+	}
+
+	return printer
+}
@@ -0,0 +1,131 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"denver/pkg/util/executor"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range []string{"text", "json", "yaml"} {
+		format, err := ParseFormat(name)
+		assert.NoError(err)
+		assert.Equal(Format(name), format)
+	}
+
+	_, err := ParseFormat("xml")
+	assert.EqualError(err, "unknown output xml, use text, json or yaml")
+}
+
+func TestTextIsWrittenAsIs(t *testing.T) {
+	assert := assert.New(t)
+	var out bytes.Buffer
+	printer := NewPrinter(Text, &out)
+	logger := log.New(printer, "", 0)
+
+	logger.Println(fmt.Sprintf("%s %s", aurora.Bold(aurora.Green("[OK]")), "done"))
+	assert.False(SetData(logger, "ignored"))
+	assert.NoError(printer.Flush("status", "v1", nil))
+
+	assert.Equal(fmt.Sprintf("%s done\n", aurora.Bold(aurora.Green("[OK]"))), out.String())
+}
+
+func TestJSONCollectsMessagesWithoutColors(t *testing.T) {
+	assert := assert.New(t)
+	var out bytes.Buffer
+	printer := NewPrinter(JSON, &out)
+	logger := log.New(printer, "", 0)
+
+	logger.Println(fmt.Sprintf("%s %s", aurora.Bold(aurora.Green("[OK]")), "Virtual machine systems are up"))
+	logger.Println(fmt.Sprintf("%s %s", aurora.Bold(aurora.Yellow("[INFO]")), "Virtual machine state is saved"))
+	logger.Print("a plain line")
+	assert.True(SetData(logger, map[string]int{"cpus": 2}))
+	printer.SetState(map[string]bool{"live": true})
+
+	assert.NoError(printer.Flush("status", "v1", nil))
+	assert.NotContains(out.String(), "\x1b[")
+
+	var result map[string]interface{}
+	assert.NoError(json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(map[string]interface{}{
+		"command": "status",
+		"ok":      true,
+		"version": "v1",
+		"state":   map[string]interface{}{"live": true},
+		"data":    map[string]interface{}{"cpus": float64(2)},
+		"messages": []interface{}{
+			map[string]interface{}{"level": "ok", "text": "Virtual machine systems are up"},
+			map[string]interface{}{"level": "info", "text": "Virtual machine state is saved"},
+			map[string]interface{}{"text": "a plain line"},
+		},
+	}, result)
+}
+
+func TestYAMLReportsErrorCodes(t *testing.T) {
+	assert := assert.New(t)
+	var out bytes.Buffer
+	printer := NewPrinter(YAML, &out)
+
+	assert.NoError(printer.Flush("snapshot restore", "v1", Errorf(CodeInvalidState, "VM is started")))
+
+	var result Result
+	assert.NoError(yaml.Unmarshal(out.Bytes(), &result))
+	assert.False(result.OK)
+	assert.Equal("snapshot restore", result.Command)
+	assert.Equal(&Error{Code: CodeInvalidState, Message: "VM is started"}, result.Error)
+	assert.Empty(result.Messages)
+}
+
+func TestStream(t *testing.T) {
+	assert := assert.New(t)
+	var out bytes.Buffer
+	logger := log.New(NewPrinter(JSON, &out), "", 0)
+
+	for i := 0; i < 2; i++ {
+		streamed, err := Stream(logger, map[string]int{"n": i})
+		assert.True(streamed)
+		assert.NoError(err)
+	}
+	assert.Equal("{\"n\":0}\n{\"n\":1}\n", out.String())
+
+	// The Result is one more line
+	out.Reset()
+	assert.NoError(logger.Writer().(*Printer).Flush("status --watch", "v1.4.0", nil))
+	assert.Equal("{\"command\":\"status --watch\",\"ok\":true,\"version\":\"v1.4.0\",\"messages\":[]}\n", out.String())
+
+	out.Reset()
+	printer := NewPrinter(YAML, &out)
+	logger = log.New(printer, "", 0)
+	_, _ = Stream(logger, map[string]int{"count": 0})
+	assert.Equal("---\ncount: 0\n", out.String())
+
+	out.Reset()
+	assert.NoError(printer.Flush("status --watch", "v1.4.0", nil))
+	assert.True(strings.HasPrefix(out.String(), "---\ncommand: status --watch\n"))
+
+	streamed, err := Stream((*log.Logger)(nil), "ignored")
+	assert.False(streamed)
+	assert.NoError(err)
+}
+
+func TestErrorOf(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(&Error{Code: CodeFailed, Message: "this is fine"}, ErrorOf(fmt.Errorf("this is fine")))
+	assert.Equal(&Error{Code: CodeInterrupted, Message: context.Canceled.Error()}, ErrorOf(context.Canceled))
+
+	exitCode := 2
+	err := &executor.Error{ExitCode: exitCode, Err: fmt.Errorf("exit status 2")}
+	assert.Equal(&Error{Code: CodeCommandFailed, Message: err.Error(), ExitCode: &exitCode}, ErrorOf(err))
+}
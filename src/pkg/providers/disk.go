@@ -0,0 +1,10 @@
+package providers
+
+// DiskGrower is implemented by the providers able to enlarge the userdata disk,
+// sizes are expressed in megabytes
+type DiskGrower interface {
+	UserDataSize() (int, error)
+	GrowUserData(size int) error
+	GuestGrowPending() (bool, error)
+	SetGuestGrowDone() error
+}
@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"log"
+)
+
+// IsReadOnlyCommand tells whether a command of the providers only reads the state of the host,
+// those are run during a dry-run so that the preview follows the actual state
+func IsReadOnlyCommand(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+
+	switch args[0] {
+	case "VBoxManage":
+		switch args[1] {
+		case "--version", "list", "showvminfo", "showmediuminfo":
+			return true
+		case "snapshot":
+			return len(args) > 3 && args[3] == "list"
+		}
+	case "qemu-img":
+		return args[1] == "info"
+	}
+
+	return false
+}
+
+// dryRunUpdater skips the download of the RBI, the box is then considered up to date
+type dryRunUpdater struct {
+	VMUpdater
+	updated bool
+}
+
+func newDryRunUpdater(updater VMUpdater) *dryRunUpdater {
+	return &dryRunUpdater{VMUpdater: updater}
+}
+
+func (u *dryRunUpdater) CheckIsUpdated() (bool, error) {
+	if u.updated {
+		return true, nil
+	}
+
+	return u.VMUpdater.CheckIsUpdated()
+}
+
+func (u *dryRunUpdater) Update() error {
+	log.Println("Dry-run, the RBI is not downloaded")
+	u.updated = true
+	return nil
+}
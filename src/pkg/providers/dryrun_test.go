@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"bytes"
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type emptyExecutor struct{}
+
+func (f emptyExecutor) Execute(args []string) (string, error) {
+	return f.ExecuteWithOptions(args, executor.Options{})
+}
+
+// Nothing is registered, the VM being previewed does not exist
+func (f emptyExecutor) ExecuteWithOptions(args []string, options executor.Options) (string, error) {
+	return "", nil
+}
+
+func TestIsReadOnlyCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsReadOnlyCommand([]string{"VBoxManage", "list", "vms"}))
+	assert.True(IsReadOnlyCommand([]string{"VBoxManage", "showvminfo", "--machinereadable", "denver"}))
+	assert.True(IsReadOnlyCommand([]string{"VBoxManage", "snapshot", "denver", "list", "--machinereadable"}))
+	assert.True(IsReadOnlyCommand([]string{"qemu-img", "info", "box.qcow2"}))
+	assert.False(IsReadOnlyCommand([]string{"VBoxManage", "snapshot", "denver", "take", "list"}))
+	assert.False(IsReadOnlyCommand([]string{"VBoxManage", "createvm", "--name", "denver"}))
+	assert.False(IsReadOnlyCommand([]string{"VBoxManage"}))
+}
+
+func TestDryRunInitOnlyPrintsTheCommands(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "dryrun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	defaultHostNetworks := hostNetworks
+	hostNetworks = func() ([]*net.IPNet, []*net.IPNet, error) { return nil, nil, nil }
+	defer func() {
+		hostNetworks = defaultHostNetworks
+	}()
+
+	var out bytes.Buffer
+	e := executor.NewDryRunExecutor(emptyExecutor{}, IsReadOnlyCommand, log.New(&out, "", 0))
+	v := newVirtualBox(structs.Provider{}, &structs.InstanceConf{
+		Name:         "denver",
+		Store:        "store",
+		Vcpu:         2,
+		Vmem:         2048,
+		Localip:      "10.10.30.10",
+		Userdatasize: 1,
+		Ports:        []string{"8080:80"},
+	}, "box.vdi", e, nil, dir)
+	v.dryRun = true
+
+	assert.NoError(v.init())
+
+	var commands []string
+	for _, command := range e.Commands() {
+		commands = append(commands, strings.Join(command[:2], " "))
+	}
+	assert.Equal([]string{
+		"VBoxManage createvm",
+		"VBoxManage modifyvm",
+		"VBoxManage modifyvm",
+		"VBoxManage modifyvm",
+		"VBoxManage modifyvm",
+		"VBoxManage hostonlyif",
+		"VBoxManage hostonlyif",
+		"VBoxManage modifyvm",
+		"VBoxManage storagectl",
+		"VBoxManage storageattach",
+		"VBoxManage createmedium",
+		"VBoxManage storageattach",
+	}, commands)
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(err)
+	assert.Empty(files)
+}
+
+func TestDryRunResumeInitLeavesTheJournal(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, dir, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	sim.FailOn("VBoxManage: error: Code E_ACCESSDENIED\n", "VBoxManage", "storagectl")
+	sim.FailOn("VBoxManage: error: Code E_ACCESSDENIED\n", "VBoxManage", "hostonlyif", "remove")
+	assert.Error(v.Init())
+
+	journal, err := ioutil.ReadFile(filepath.Join(dir, "store", "init.json"))
+	assert.NoError(err)
+
+	var out bytes.Buffer
+	e := executor.NewDryRunExecutor(sim, IsReadOnlyCommand, log.New(&out, "", 0))
+	v.executor = e
+	v.dryRun = true
+
+	assert.NoError(v.ResumeInit())
+	var commands []string
+	for _, command := range e.Commands() {
+		commands = append(commands, strings.Join(command[:2], " "))
+	}
+	assert.Equal([]string{
+		"VBoxManage modifyvm",
+		"VBoxManage storagectl",
+		"VBoxManage storageattach",
+		"VBoxManage createmedium",
+		"VBoxManage storageattach",
+	}, commands)
+
+	// The init can still be resumed for real
+	after, err := ioutil.ReadFile(filepath.Join(dir, "store", "init.json"))
+	assert.NoError(err)
+	assert.Equal(string(journal), string(after))
+	_, err = os.Stat(v.instanceStates.path)
+	assert.True(os.IsNotExist(err))
+}
@@ -0,0 +1,9 @@
+package providers
+
+import "denver/pkg/providers/virtualbox"
+
+// Exportable is implemented by the providers whose instance can be exported
+type Exportable interface {
+	UserDataPath() string
+	GetRBIManifest() (virtualbox.Manifest, error)
+}
@@ -0,0 +1,275 @@
+package providers
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Transition is a change of the state of a Fake, applied once Delay has elapsed
+type Transition struct {
+	Delay time.Duration
+	State State
+}
+
+// BootTransitions returns the transitions of a VM booting, one step every delay
+func BootTransitions(delay time.Duration) []Transition {
+	return []Transition{
+		{Delay: delay, State: State{Live: true}},
+		{Delay: delay, State: State{Live: true, OsReady: true}},
+		{Delay: delay, State: State{Live: true, OsReady: true, AllSystemsReady: true}},
+	}
+}
+
+// ShutdownTransitions returns the transitions of a VM shutting down after delay
+func ShutdownTransitions(delay time.Duration) []Transition {
+	return []Transition{
+		{Delay: delay, State: State{}},
+	}
+}
+
+// Fake is a VM provider whose behaviour is scripted, to test the actions without an hypervisor.
+// Once a method has been called, the state follows the transitions set for it with Script, or
+// the method fails with the error set with Fail. It also supports suspending and resuming init.
+type Fake struct {
+	mutex       sync.Mutex
+	states      *StateStore
+	upToDate    bool
+	transitions map[string][]Transition
+	errors      map[string]error
+	calls       []string
+	resumed     bool
+	script      chan struct{}
+
+	*lifecycle
+}
+
+// NewFake returns a pointer to a Fake in the given state, its RBI is up to date
+func NewFake(state State) *Fake {
+	states := NewStateStore()
+	states.Set(state)
+	return &Fake{
+		states:      states,
+		upToDate:    true,
+		transitions: map[string][]Transition{},
+		errors:      map[string]error{},
+		lifecycle:   newLifecycle(),
+	}
+}
+
+// Script sets the transitions following a call to method
+func (f *Fake) Script(method string, transitions ...Transition) *Fake {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.transitions[method] = transitions
+	return f
+}
+
+// Fail makes method return err, without changing the state
+func (f *Fake) Fail(method string, err error) *Fake {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.errors[method] = err
+	return f
+}
+
+// SetUpToDate sets whether the RBI is up to date, Update makes it up to date
+func (f *Fake) SetUpToDate(upToDate bool) *Fake {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.upToDate = upToDate
+	return f
+}
+
+// Calls returns the names of the methods called, in order
+func (f *Fake) Calls() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string{}, f.calls...)
+}
+
+// Init VM
+func (f *Fake) Init() error {
+	return f.call("Init")
+}
+
+// ResumeInit continues an interrupted Init
+func (f *Fake) ResumeInit() error {
+	return f.call("ResumeInit")
+}
+
+// Start VM, a saved VM is resumed
+func (f *Fake) Start() error {
+	if err := f.run(PreStart); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	f.resumed = f.states.Get().Saved
+	f.mutex.Unlock()
+
+	return f.callExpecting("Start", PostStart)
+}
+
+// Stop VM, the pre-stop actions are run first
+func (f *Fake) Stop() error {
+	if err := f.run(PreStop); err != nil {
+		return err
+	}
+
+	return f.callExpecting("Stop", PostStop)
+}
+
+// PowerOff VM
+func (f *Fake) PowerOff() error {
+	return f.callExpecting("PowerOff", PostStop)
+}
+
+// Suspend VM
+func (f *Fake) Suspend() error {
+	if err := f.run(PreStop); err != nil {
+		return err
+	}
+
+	return f.callExpecting("Suspend", PostStop)
+}
+
+// IsSaved tells whether the VM has been suspended
+func (f *Fake) IsSaved() (bool, error) {
+	if err := f.call("IsSaved"); err != nil {
+		return false, err
+	}
+
+	return f.GetState().Saved, nil
+}
+
+// Resumed tells whether the last Start restored a saved state
+func (f *Fake) Resumed() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.resumed
+}
+
+// Unregister VM
+func (f *Fake) Unregister() error {
+	return f.call("Unregister")
+}
+
+// Update VM, the pre-update and post-update actions are run when the RBI is not up to date
+func (f *Fake) Update() (updated bool, err error) {
+	f.mutex.Lock()
+	updated = !f.upToDate
+	f.mutex.Unlock()
+
+	if updated {
+		if err = f.run(PreUpdate); err != nil {
+			return false, err
+		}
+	}
+
+	if err = f.call("Update"); err != nil {
+		return false, err
+	}
+
+	f.mutex.Lock()
+	f.upToDate = true
+	f.mutex.Unlock()
+
+	if updated {
+		err = f.run(PostUpdate)
+	}
+	return
+}
+
+// CheckIsUpdated VM
+func (f *Fake) CheckIsUpdated() (bool, error) {
+	if err := f.call("CheckIsUpdated"); err != nil {
+		return false, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.upToDate, nil
+}
+
+// GetState returns a copy of the current state, it can be read while the transitions go on
+func (f *Fake) GetState() *State {
+	return f.states.Get()
+}
+
+// StateStore VM
+func (f *Fake) StateStore() *StateStore {
+	return f.states
+}
+
+func (f *Fake) checkIfRunning() (bool, error) {
+	return f.GetState().Live, nil
+}
+
+func (f *Fake) setState(state *State) error {
+	return f.publish(f.states, *state)
+}
+
+// call records the call of method then either returns its error or plays its transitions,
+// the ones of a previous call still pending are dropped
+func (f *Fake) call(method string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls = append(f.calls, method)
+
+	if err, ok := f.errors[method]; ok {
+		return err
+	}
+
+	transitions, ok := f.transitions[method]
+	if !ok {
+		return nil
+	}
+	if f.script != nil {
+		close(f.script)
+	}
+	f.script = make(chan struct{})
+	go f.play(f.script, transitions)
+
+	return nil
+}
+
+// callExpecting calls method, the actions of point run on the transitions it leads to
+func (f *Fake) callExpecting(method string, point LifecyclePoint) error {
+	f.expect(point)
+	if err := f.call(method); err != nil {
+		f.unexpect(point)
+		return err
+	}
+
+	return nil
+}
+
+func (f *Fake) play(script chan struct{}, transitions []Transition) {
+	for _, transition := range transitions {
+		select {
+		case <-time.After(transition.Delay):
+		case <-script:
+			return
+		}
+
+		// Like the probe, a failing post-start or post-stop action does not change the state
+		if err := f.executeTransitionActions(transition.State); err != nil {
+			log.Println(err)
+		}
+
+		f.mutex.Lock()
+		select {
+		case <-script:
+		default:
+			f.states.Set(transition.State)
+		}
+		f.mutex.Unlock()
+	}
+}
+
+// executeTransitionActions runs the post-start and post-stop actions the transition to state leads to,
+// before the new state is visible so that they are done once it is
+func (f *Fake) executeTransitionActions(state State) error {
+	return f.runOnEvents(stateEvents(*f.states.Get(), state, time.Now()))
+}
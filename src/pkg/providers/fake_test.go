@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitForState(f *Fake, ready func(*State) bool) bool {
+	for i := 0; i < 100; i++ {
+		if ready(f.GetState()) {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestFakePlaysTheTransitionsOfAMethod(t *testing.T) {
+	assert := assert.New(t)
+	fake := NewFake(State{}).Script("Start", BootTransitions(10*time.Millisecond)...)
+	postStarted := 0
+	fake.AddPostStartAction(func() error {
+		postStarted++
+		return nil
+	})
+
+	assert.NoError(fake.Start())
+	assert.True(waitForState(fake, func(s *State) bool { return s.AllSystemsReady }))
+	assert.Equal(&State{Live: true, OsReady: true, AllSystemsReady: true}, fake.GetState())
+	assert.Equal(1, postStarted)
+	assert.Equal([]string{"Start"}, fake.Calls())
+}
+
+func TestFakeDropsThePendingTransitions(t *testing.T) {
+	assert := assert.New(t)
+	fake := NewFake(State{}).
+		Script("Start", BootTransitions(200*time.Millisecond)...).
+		Script("PowerOff", ShutdownTransitions(0)...)
+
+	assert.NoError(fake.Start())
+	assert.NoError(fake.PowerOff())
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(&State{}, fake.GetState())
+}
+
+func TestFakeFailsWithoutChangingTheState(t *testing.T) {
+	assert := assert.New(t)
+	fake := NewFake(State{Live: true}).
+		Script("Stop", ShutdownTransitions(0)...).
+		Fail("Stop", fmt.Errorf("this is fine"))
+
+	assert.EqualError(fake.Stop(), "this is fine")
+	time.Sleep(50 * time.Millisecond)
+	assert.True(fake.GetState().Live)
+}
+
+func TestFakeUpdatesTheRBI(t *testing.T) {
+	assert := assert.New(t)
+	fake := NewFake(State{}).SetUpToDate(false)
+
+	upToDate, err := fake.CheckIsUpdated()
+	assert.NoError(err)
+	assert.False(upToDate)
+
+	updated, err := fake.Update()
+	assert.NoError(err)
+	assert.True(updated)
+
+	upToDate, err = fake.CheckIsUpdated()
+	assert.NoError(err)
+	assert.True(upToDate)
+}
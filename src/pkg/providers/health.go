@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HTTP checks give up after this many seconds
+const healthCheckHTTPTimeout = 5
+
+// CheckResult is the last result of a health check of the guest, Error tells why it failed
+type CheckResult struct {
+	Name  string `json:"name" yaml:"name"`
+	OK    bool   `json:"ok" yaml:"ok"`
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// guestCommander runs a command in the guest, like ssh.SSH
+type guestCommander interface {
+	Cmd(command string) (string, error)
+}
+
+// healthCheck is a validated health check, run reports why the system is down
+type healthCheck struct {
+	name string
+	run  func(guest guestCommander) error
+}
+
+// newHealthChecks validates the checks declared in the configuration
+func newHealthChecks(confs []structs.HealthCheck) (checks []healthCheck, err error) {
+	names := map[string]bool{}
+	for i, conf := range confs {
+		if conf.Name == "" {
+			return nil, fmt.Errorf("health check %d has no name", i+1)
+		}
+		if names[conf.Name] {
+			return nil, fmt.Errorf("health check %s is declared twice", conf.Name)
+		}
+		names[conf.Name] = true
+
+		check, err := newHealthCheck(conf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid health check %s: %s", conf.Name, err)
+		}
+		checks = append(checks, check)
+	}
+
+	return
+}
+
+func newHealthCheck(conf structs.HealthCheck) (check healthCheck, err error) {
+	var kinds []string
+	check.name = conf.Name
+
+	if conf.Unit != "" {
+		kinds = append(kinds, "unit")
+		check.run = unitCheck(conf.Unit)
+	}
+	if conf.Port != 0 {
+		if conf.Port < 0 || conf.Port > 65535 {
+			return check, fmt.Errorf("port %d is out of range", conf.Port)
+		}
+		kinds = append(kinds, "port")
+		check.run = portCheck(conf.Port)
+	}
+	if conf.HTTP != "" {
+		if !strings.HasPrefix(conf.HTTP, "http://") && !strings.HasPrefix(conf.HTTP, "https://") {
+			return check, fmt.Errorf("%s is not an HTTP URL", conf.HTTP)
+		}
+		kinds = append(kinds, "http")
+		check.run = httpCheck(conf.HTTP)
+	}
+	if conf.Command != "" {
+		kinds = append(kinds, "command")
+		check.run = commandCheck(conf.Command)
+	}
+
+	switch len(kinds) {
+	case 0:
+		return check, fmt.Errorf("one of unit, port, http or command is required")
+	case 1:
+		return check, nil
+	}
+
+	return check, fmt.Errorf("only one of %s can be set", strings.Join(kinds, ", "))
+}
+
+func unitCheck(unit string) func(guestCommander) error {
+	return func(guest guestCommander) error {
+		if _, err := guest.Cmd(fmt.Sprintf("systemctl is-active --quiet %s", executor.ShellQuote(unit))); err != nil {
+			return fmt.Errorf("unit %s is not active", unit)
+		}
+		return nil
+	}
+}
+
+func portCheck(port int) func(guestCommander) error {
+	return func(guest guestCommander) error {
+		if _, err := guest.Cmd(fmt.Sprintf("ss -Hltn 'sport = :%d' | grep -q .", port)); err != nil {
+			return fmt.Errorf("nothing listens on port %d", port)
+		}
+		return nil
+	}
+}
+
+func httpCheck(url string) func(guestCommander) error {
+	return func(guest guestCommander) error {
+		out, err := guest.Cmd(fmt.Sprintf(
+			"curl -s -o /dev/null -w '%%{http_code}' --max-time %d %s",
+			healthCheckHTTPTimeout,
+			executor.ShellQuote(url),
+		))
+		if err != nil {
+			return fmt.Errorf("%s is unreachable", url)
+		}
+
+		status, err := strconv.Atoi(strings.TrimSpace(out))
+		if err != nil || status < 200 || status > 299 {
+			return fmt.Errorf("%s answered with status %s", url, strings.TrimSpace(out))
+		}
+		return nil
+	}
+}
+
+func commandCheck(command string) func(guestCommander) error {
+	return func(guest guestCommander) error {
+		if out, err := guest.Cmd(command); err != nil {
+			if out = strings.TrimSpace(out); out != "" {
+				return fmt.Errorf("%s: %s", err, out)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// runHealthChecks runs every check, ok tells whether they all pass
+func runHealthChecks(guest guestCommander, checks []healthCheck) (results []CheckResult, ok bool) {
+	ok = true
+	for _, check := range checks {
+		result := CheckResult{Name: check.name, OK: true}
+		if err := check.run(guest); err != nil {
+			result.OK = false
+			result.Error = err.Error()
+			ok = false
+		}
+		results = append(results, result)
+	}
+
+	return
+}
@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"denver/structs"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingGuest struct {
+	outputs  map[string]string
+	failures map[string]bool
+	commands []string
+}
+
+func (g *testingGuest) Cmd(command string) (string, error) {
+	g.commands = append(g.commands, command)
+	if g.failures[command] {
+		return g.outputs[command], fmt.Errorf("Process exited with status 1")
+	}
+	return g.outputs[command], nil
+}
+
+func TestHealthChecksAreValidated(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, test := range []struct {
+		checks []structs.HealthCheck
+		err    string
+	}{
+		{[]structs.HealthCheck{{Unit: "mysql"}}, "health check 1 has no name"},
+		{[]structs.HealthCheck{{Name: "db", Unit: "mysql"}, {Name: "db", Port: 3306}}, "health check db is declared twice"},
+		{[]structs.HealthCheck{{Name: "db"}}, "invalid health check db: one of unit, port, http or command is required"},
+		{[]structs.HealthCheck{{Name: "db", Unit: "mysql", Port: 3306}}, "invalid health check db: only one of unit, port can be set"},
+		{[]structs.HealthCheck{{Name: "db", Port: 70000}}, "invalid health check db: port 70000 is out of range"},
+		{[]structs.HealthCheck{{Name: "app", HTTP: "localhost"}}, "invalid health check app: localhost is not an HTTP URL"},
+	} {
+		_, err := newHealthChecks(test.checks)
+		assert.EqualError(err, test.err)
+	}
+}
+
+func TestHealthChecksRunInTheGuest(t *testing.T) {
+	assert := assert.New(t)
+	checks, err := newHealthChecks([]structs.HealthCheck{
+		{Name: "mysql", Unit: "mysql"},
+		{Name: "nginx", Port: 80},
+		{Name: "app", HTTP: "http://localhost/health"},
+		{Name: "nfs", Command: "showmount -e localhost"},
+	})
+	assert.NoError(err)
+	guest := &testingGuest{outputs: map[string]string{
+		"curl -s -o /dev/null -w '%{http_code}' --max-time 5 'http://localhost/health'": "204",
+	}}
+
+	results, ok := runHealthChecks(guest, checks)
+	assert.True(ok)
+	assert.Equal([]CheckResult{
+		{Name: "mysql", OK: true},
+		{Name: "nginx", OK: true},
+		{Name: "app", OK: true},
+		{Name: "nfs", OK: true},
+	}, results)
+	assert.Equal([]string{
+		"systemctl is-active --quiet 'mysql'",
+		"ss -Hltn 'sport = :80' | grep -q .",
+		"curl -s -o /dev/null -w '%{http_code}' --max-time 5 'http://localhost/health'",
+		"showmount -e localhost",
+	}, guest.commands)
+}
+
+func TestHealthChecksReportTheFailures(t *testing.T) {
+	assert := assert.New(t)
+	checks, err := newHealthChecks([]structs.HealthCheck{
+		{Name: "mysql", Unit: "mysql"},
+		{Name: "nginx", Port: 80},
+		{Name: "app", HTTP: "http://localhost/health"},
+		{Name: "nfs", Command: "showmount -e localhost"},
+	})
+	assert.NoError(err)
+	guest := &testingGuest{
+		outputs: map[string]string{
+			"curl -s -o /dev/null -w '%{http_code}' --max-time 5 'http://localhost/health'": "502",
+			"showmount -e localhost": "clnt_create: RPC: Program not registered\n",
+		},
+		failures: map[string]bool{
+			"systemctl is-active --quiet 'mysql'": true,
+			"ss -Hltn 'sport = :80' | grep -q .":  true,
+			"showmount -e localhost":              true,
+		},
+	}
+
+	results, ok := runHealthChecks(guest, checks)
+	assert.False(ok)
+	assert.Equal([]CheckResult{
+		{Name: "mysql", Error: "unit mysql is not active"},
+		{Name: "nginx", Error: "nothing listens on port 80"},
+		{Name: "app", Error: "http://localhost/health answered with status 502"},
+		{Name: "nfs", Error: "Process exited with status 1: clnt_create: RPC: Program not registered"},
+	}, results)
+}
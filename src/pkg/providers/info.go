@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"time"
+)
+
+// Informer is implemented by the providers able to describe their VM
+type Informer interface {
+	Info() (*VMInfo, error)
+}
+
+// VMInfo describes a VM as seen by its hypervisor, Uptime is only set while it is running
+type VMInfo struct {
+	Name       string              `json:"name" yaml:"name"`
+	UUID       string              `json:"uuid" yaml:"uuid"`
+	State      string              `json:"state" yaml:"state"`
+	StateSince time.Time           `json:"stateSince" yaml:"stateSince"`
+	Uptime     string              `json:"uptime,omitempty" yaml:"uptime,omitempty"`
+	CPUs       int                 `json:"cpus" yaml:"cpus"`
+	Memory     int                 `json:"memory" yaml:"memory"`
+	NICs       []NIC               `json:"nics" yaml:"nics"`
+	Storage    []StorageAttachment `json:"storage" yaml:"storage"`
+	Snapshots  []string            `json:"snapshots" yaml:"snapshots"`
+}
+
+// NIC describes a network adapter of the VM, Network is the host interface it is bound to if any
+type NIC struct {
+	Index   int    `json:"index" yaml:"index"`
+	Type    string `json:"type" yaml:"type"`
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+	MAC     string `json:"mac" yaml:"mac"`
+}
+
+// StorageAttachment describes a medium attached to a storage controller of the VM
+type StorageAttachment struct {
+	Controller string `json:"controller" yaml:"controller"`
+	Port       int    `json:"port" yaml:"port"`
+	Device     int    `json:"device" yaml:"device"`
+	Medium     string `json:"medium" yaml:"medium"`
+	UUID       string `json:"uuid,omitempty" yaml:"uuid,omitempty"`
+}
@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// InitResumer is implemented by the providers able to continue an interrupted init
+type InitResumer interface {
+	ResumeInit() error
+}
+
+// initStep is a reversible step of an init, undo is nil when the step needs no cleanup
+type initStep struct {
+	name string
+	do   func() error
+	undo func() error
+}
+
+// initProgress records the steps done by an init and what they created
+type initProgress struct {
+	Steps             []string `json:"steps"`
+	HostOnlyIf        string   `json:"hostOnlyIf,omitempty"`
+	HostOnlyIfCreated bool     `json:"hostOnlyIfCreated,omitempty"`
+	UserDataCreated   bool     `json:"userDataCreated,omitempty"`
+}
+
+func (p *initProgress) isDone(name string) bool {
+	for _, step := range p.Steps {
+		if step == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *initProgress) remove(name string) {
+	var steps []string
+	for _, step := range p.Steps {
+		if step != name {
+			steps = append(steps, step)
+		}
+	}
+	p.Steps = steps
+}
+
+// initJournal persists the progress of an init so that it can be resumed
+type initJournal struct {
+	path string
+}
+
+func newInitJournal(storePath string) *initJournal {
+	return &initJournal{
+		path: filepath.Join(storePath, "init.json"),
+	}
+}
+
+func (j *initJournal) exists() (bool, error) {
+	_, err := os.Stat(j.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (j *initJournal) read() (progress *initProgress, err error) {
+	body, err := ioutil.ReadFile(j.path)
+	if err != nil {
+		return
+	}
+
+	progress = &initProgress{}
+	err = json.Unmarshal(body, progress)
+	return
+}
+
+func (j *initJournal) write(progress *initProgress) (err error) {
+	body, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(j.path), os.ModePerm); err != nil {
+		return
+	}
+
+	return ioutil.WriteFile(j.path, body, 0644)
+}
+
+func (j *initJournal) remove() error {
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// runInitSteps runs the steps which are not done yet, recording each of them in the journal.
+// On failure, the steps done are undone in reverse order and the journal only keeps the ones
+// which could not be undone, so that the init can be resumed or started again.
+func runInitSteps(journal *initJournal, progress *initProgress, steps []initStep) (err error) {
+	for i, step := range steps {
+		if progress.isDone(step.name) {
+			continue
+		}
+
+		if err = step.do(); err != nil {
+			if rollbackErr := rollbackInitSteps(journal, progress, steps[:i]); rollbackErr != nil {
+				return fmt.Errorf("%s\nthe cleanup failed too, fix it then run init --resume: %s", err, rollbackErr)
+			}
+			return
+		}
+
+		progress.Steps = append(progress.Steps, step.name)
+		if err = journal.write(progress); err != nil {
+			return
+		}
+	}
+
+	return journal.remove()
+}
+
+func rollbackInitSteps(journal *initJournal, progress *initProgress, steps []initStep) (err error) {
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if !progress.isDone(step.name) {
+			continue
+		}
+
+		if step.undo != nil {
+			log.Printf("Undoing %s", step.name)
+			if err = step.undo(); err != nil {
+				_ = journal.write(progress)
+				return
+			}
+		}
+		progress.remove(step.name)
+	}
+
+	return journal.remove()
+}
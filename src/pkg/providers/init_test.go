@@ -0,0 +1,93 @@
+package providers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getInitJournal(t *testing.T) (*initJournal, func()) {
+	dir, err := ioutil.TempDir("", "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newInitJournal(dir), func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func recordingStep(name string, calls *[]string, failing bool) initStep {
+	return initStep{
+		name: name,
+		do: func() error {
+			*calls = append(*calls, "do "+name)
+			if failing {
+				return fmt.Errorf("%s failed", name)
+			}
+			return nil
+		},
+		undo: func() error {
+			*calls = append(*calls, "undo "+name)
+			return nil
+		},
+	}
+}
+
+func TestFailedInitIsUndoneInReverseOrder(t *testing.T) {
+	assert := assert.New(t)
+	journal, clean := getInitJournal(t)
+	defer clean()
+	var calls []string
+
+	err := runInitSteps(journal, &initProgress{}, []initStep{
+		recordingStep("createvm", &calls, false),
+		{name: "options", do: func() error { return nil }},
+		recordingStep("hostonlyif", &calls, false),
+		recordingStep("attach-root", &calls, true),
+	})
+
+	assert.EqualError(err, "attach-root failed")
+	assert.Equal([]string{"do createvm", "do hostonlyif", "do attach-root", "undo hostonlyif", "undo createvm"}, calls)
+
+	exists, err := journal.exists()
+	assert.NoError(err)
+	assert.False(exists)
+}
+
+func TestInitCanBeResumedWhenTheCleanupFails(t *testing.T) {
+	assert := assert.New(t)
+	journal, clean := getInitJournal(t)
+	defer clean()
+	var calls []string
+
+	createvm := recordingStep("createvm", &calls, false)
+	createvm.undo = func() error { return fmt.Errorf("VM is locked") }
+
+	err := runInitSteps(journal, &initProgress{}, []initStep{
+		createvm,
+		recordingStep("hostonlyif", &calls, false),
+		recordingStep("attach-root", &calls, true),
+	})
+	assert.EqualError(err, "attach-root failed\nthe cleanup failed too, fix it then run init --resume: VM is locked")
+
+	progress, err := journal.read()
+	assert.NoError(err)
+	assert.Equal([]string{"createvm"}, progress.Steps)
+
+	calls = nil
+	err = runInitSteps(journal, progress, []initStep{
+		createvm,
+		recordingStep("hostonlyif", &calls, false),
+		recordingStep("attach-root", &calls, false),
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"do hostonlyif", "do attach-root"}, calls)
+
+	exists, err := journal.exists()
+	assert.NoError(err)
+	assert.False(exists)
+}
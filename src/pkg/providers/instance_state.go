@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// InstanceStateVersion is bumped on every incompatible change of state.json
+const InstanceStateVersion = 1
+
+// InstanceStater is implemented by the providers recording what they created for an instance
+type InstanceStater interface {
+	InstanceState() (*InstanceState, error)
+	InstanceDrift() ([]string, error)
+}
+
+// InstanceState describes the resources created for an instance. Discovered is set when it
+// has been rebuilt from the hypervisor because state.json is missing.
+type InstanceState struct {
+	Version           int       `json:"version" yaml:"version"`
+	VMUUID            string    `json:"vmUuid" yaml:"vmUuid"`
+	HostOnlyIf        string    `json:"hostOnlyIf" yaml:"hostOnlyIf"`
+	HostOnlyIfCreated bool      `json:"hostOnlyIfCreated" yaml:"hostOnlyIfCreated"`
+	RootMedium        string    `json:"rootMedium" yaml:"rootMedium"`
+	UserDataMedium    string    `json:"userDataMedium" yaml:"userDataMedium"`
+	RBIVersion        string    `json:"rbiVersion" yaml:"rbiVersion"`
+	CreatedAt         time.Time `json:"createdAt" yaml:"createdAt"`
+	Discovered        bool      `json:"-" yaml:"-"`
+}
+
+// instanceStateFile persists the InstanceState in the store of the instance
+type instanceStateFile struct {
+	path string
+}
+
+func newInstanceStateFile(storePath string) *instanceStateFile {
+	return &instanceStateFile{
+		path: filepath.Join(storePath, "state.json"),
+	}
+}
+
+// read returns nil when the file does not exist
+func (f *instanceStateFile) read() (state *InstanceState, err error) {
+	body, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return
+	}
+
+	state = &InstanceState{}
+	if err = json.Unmarshal(body, state); err != nil {
+		return nil, err
+	}
+
+	if state.Version != InstanceStateVersion {
+		return nil, fmt.Errorf("unsupported version %d of %s", state.Version, f.path)
+	}
+
+	return
+}
+
+func (f *instanceStateFile) write(state *InstanceState) (err error) {
+	state.Version = InstanceStateVersion
+
+	body, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(f.path), os.ModePerm); err != nil {
+		return
+	}
+
+	return ioutil.WriteFile(f.path, body, 0644)
+}
+
+func (f *instanceStateFile) remove() error {
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
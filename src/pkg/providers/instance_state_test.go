@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceStateFileIsVersioned(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "state")
+	assert.NoError(err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	file := newInstanceStateFile(dir)
+
+	state, err := file.read()
+	assert.NoError(err)
+	assert.Nil(state, "a missing file is not an error")
+
+	assert.NoError(file.write(&InstanceState{VMUUID: "6e3b1c2a", RBIVersion: "1.2.0"}))
+	state, err = file.read()
+	assert.NoError(err)
+	assert.Equal(InstanceStateVersion, state.Version)
+	assert.Equal("6e3b1c2a", state.VMUUID)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "state.json"), []byte(`{"version": 42}`), 0644))
+	_, err = file.read()
+	assert.Error(err)
+}
+
+func TestInstanceDrift(t *testing.T) {
+	assert := assert.New(t)
+
+	recorded := &InstanceState{
+		VMUUID:         "6e3b1c2a",
+		HostOnlyIf:     "vboxnet0",
+		RootMedium:     "a1",
+		UserDataMedium: "b2",
+	}
+
+	info := parseMachineReadable(`UUID="6e3b1c2a"
+hostonlyadapter2="vboxnet0"
+"SAS-0-0"="/home/j.doe/denver/store/stable/box.vdi"
+"SAS-ImageUUID-0-0"="a1"
+"SAS-1-0"="none"
+`)
+
+	assert.Equal([]string{"userdata disk is none instead of b2"}, instanceDrift(recorded, info))
+
+	recorded.UserDataMedium = ""
+	assert.Empty(instanceDrift(recorded, info))
+
+	info["CurrentSnapshotUUID"] = "c3"
+	info["SAS-ImageUUID-0-0"] = "d4"
+	assert.Empty(instanceDrift(recorded, info), "disks are not compared once a snapshot is taken")
+}
@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"sync"
+	"time"
+)
+
+// LifecyclePoint is a step of the life of a VM which actions can be attached to
+type LifecyclePoint string
+
+const (
+	// PreStart actions run before the VM is started or resumed, a failure cancels the start
+	PreStart LifecyclePoint = "pre-start"
+	// PostStart actions run once all the systems of the VM are ready
+	PostStart LifecyclePoint = "post-start"
+	// PreStop actions run before the VM is stopped or suspended, a failure cancels the stop
+	PreStop LifecyclePoint = "pre-stop"
+	// PostStop actions run once the VM has gone down
+	PostStop LifecyclePoint = "post-stop"
+	// PreUpdate actions run before the VM is recreated from a new RBI, a failure cancels the update
+	PreUpdate LifecyclePoint = "pre-update"
+	// PostUpdate actions run once the VM has been recreated from the new RBI
+	PostUpdate LifecyclePoint = "post-update"
+)
+
+// lifecycle holds the actions attached to the points of the life of a VM, they run in the order
+// they have been added. The post-start and post-stop actions only follow a start or a stop of
+// this process, not a VM found running or stopped, and run once for each of them.
+type lifecycle struct {
+	mutex    sync.Mutex
+	actions  map[LifecyclePoint][]func() error
+	expected map[LifecyclePoint]bool
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		actions:  map[LifecyclePoint][]func() error{},
+		expected: map[LifecyclePoint]bool{},
+	}
+}
+
+// AddAction triggers an action at point
+func (l *lifecycle) AddAction(point LifecyclePoint, action func() error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.actions[point] = append(l.actions[point], action)
+}
+
+// AddPostStartAction triggers an action once all the systems are ready
+func (l *lifecycle) AddPostStartAction(action func() error) {
+	l.AddAction(PostStart, action)
+}
+
+// AddPreStopAction triggers an action just before stopping the VM
+func (l *lifecycle) AddPreStopAction(action func() error) {
+	l.AddAction(PreStop, action)
+}
+
+// expect makes the next transition to point run its actions, once the VM has been asked to start or stop
+func (l *lifecycle) expect(point LifecyclePoint) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.expected[point] = true
+}
+
+// unexpect forgets point, the VM has not been asked to start or stop after all
+func (l *lifecycle) unexpect(point LifecyclePoint) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.expected, point)
+}
+
+// run runs the actions of point, the first failure stops them
+func (l *lifecycle) run(point LifecyclePoint) error {
+	l.mutex.Lock()
+	actions := l.actions[point]
+	l.mutex.Unlock()
+
+	for _, action := range actions {
+		if err := action(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// publish runs the actions the transition to state leads to then publishes it, so that the actions
+// are done once whoever waits for the state gets it
+func (l *lifecycle) publish(states *StateStore, state State) error {
+	err := l.runOnEvents(stateEvents(*states.Get(), state, time.Now()))
+	states.Set(state)
+	return err
+}
+
+// runOnEvents runs the actions of the expected points the transitions of the state lead to
+func (l *lifecycle) runOnEvents(events []StateEvent) error {
+	for _, event := range events {
+		var point LifecyclePoint
+		switch event.Type {
+		case EventSystemsReady:
+			point = PostStart
+		case EventWentDown:
+			point = PostStop
+		default:
+			continue
+		}
+
+		l.mutex.Lock()
+		expected := l.expected[point]
+		delete(l.expected, point)
+		if point == PostStop {
+			// The VM went down before being ready, there is nothing to run for this start anymore
+			delete(l.expected, PostStart)
+		}
+		l.mutex.Unlock()
+
+		if expected {
+			return l.run(point)
+		}
+	}
+
+	return nil
+}
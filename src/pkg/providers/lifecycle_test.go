@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleRunsTheActionsInOrder(t *testing.T) {
+	assert := assert.New(t)
+	l := newLifecycle()
+	var ran []string
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		l.AddAction(PreStart, func() error {
+			ran = append(ran, name)
+			if name == "second" {
+				return fmt.Errorf("%s failed", name)
+			}
+			return nil
+		})
+	}
+
+	assert.EqualError(l.run(PreStart), "second failed")
+	assert.Equal([]string{"first", "second"}, ran)
+	assert.NoError(l.run(PostUpdate))
+}
+
+func TestLifecycleRunsOnTheExpectedTransitions(t *testing.T) {
+	assert := assert.New(t)
+	l := newLifecycle()
+	var ran []LifecyclePoint
+	for _, point := range []LifecyclePoint{PostStart, PostStop} {
+		point := point
+		l.AddAction(point, func() error {
+			ran = append(ran, point)
+			return nil
+		})
+	}
+	ready := State{Live: true, OsReady: true, AllSystemsReady: true}
+
+	// Found running, nothing has been asked
+	assert.NoError(l.runOnEvents(stateEvents(State{}, ready, time.Now())))
+	assert.Empty(ran)
+
+	l.expect(PostStop)
+	assert.NoError(l.runOnEvents(stateEvents(ready, State{Saved: true}, time.Now())))
+	l.expect(PostStart)
+	assert.NoError(l.runOnEvents(stateEvents(State{}, State{Live: true}, time.Now())))
+	assert.NoError(l.runOnEvents(stateEvents(State{Live: true}, ready, time.Now())))
+	// Once per start, a flapping system does not run them again
+	assert.NoError(l.runOnEvents(stateEvents(State{Live: true}, ready, time.Now())))
+	assert.Equal([]LifecyclePoint{PostStop, PostStart}, ran)
+}
+
+func TestLifecyclePublishesOnceTheActionsAreDone(t *testing.T) {
+	assert := assert.New(t)
+	l := newLifecycle()
+	states := NewStateStore()
+	states.Set(State{Live: true})
+	events, unsubscribe := states.Subscribe()
+	defer unsubscribe()
+
+	done := false
+	l.AddAction(PostStop, func() error {
+		time.Sleep(50 * time.Millisecond)
+		done = true
+		return fmt.Errorf("vpn is still up")
+	})
+	l.expect(PostStop)
+
+	go func() {
+		_ = l.publish(states, State{})
+	}()
+
+	select {
+	case event := <-events:
+		assert.Equal(EventWentDown, event.Type)
+		assert.True(done)
+	case <-time.After(time.Second):
+		assert.Fail("the state has not been published")
+	}
+}
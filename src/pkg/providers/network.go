@@ -0,0 +1,6 @@
+package providers
+
+// NetworkPruner is implemented by the providers able to clean the host networks left behind
+type NetworkPruner interface {
+	PruneNetworks() ([]string, error)
+}
@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PortForwarder is implemented by the providers able to forward host ports to the VM
+type PortForwarder interface {
+	AddPortForward(forward PortForward) error
+	RemovePortForward(forward PortForward) error
+	ListPortForwards() ([]PortForward, error)
+}
+
+// PortForward maps a TCP port of the host loopback to a port of the VM
+type PortForward struct {
+	Host  int `json:"host" yaml:"host"`
+	Guest int `json:"guest" yaml:"guest"`
+}
+
+// ParsePortForward parses a <host>:<guest> definition
+func ParsePortForward(definition string) (forward PortForward, err error) {
+	parts := strings.Split(definition, ":")
+	if len(parts) != 2 {
+		return forward, fmt.Errorf("invalid port forward %s, expected <host>:<guest>", definition)
+	}
+
+	if forward.Host, err = parsePort(parts[0]); err != nil {
+		return forward, fmt.Errorf("invalid port forward %s, %s", definition, err.Error())
+	}
+	if forward.Guest, err = parsePort(parts[1]); err != nil {
+		return forward, fmt.Errorf("invalid port forward %s, %s", definition, err.Error())
+	}
+
+	return
+}
+
+// ParsePortForwards parses every definition, the first invalid one is an error
+func ParsePortForwards(definitions []string) (forwards []PortForward, err error) {
+	for _, definition := range definitions {
+		forward, err := ParsePortForward(definition)
+		if err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, forward)
+	}
+
+	return
+}
+
+func (p PortForward) String() string {
+	return fmt.Sprintf("%d:%d", p.Host, p.Guest)
+}
+
+func parsePort(port string) (int, error) {
+	value, err := strconv.Atoi(strings.TrimSpace(port))
+	if err != nil || value < 1 || value > 65535 {
+		return 0, fmt.Errorf("%s is not a valid port", port)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"denver/pkg/host"
+	"fmt"
+)
+
+// Preflighter is implemented by the providers able to check the host before operating a VM
+type Preflighter interface {
+	Preflight() error
+}
+
+func checkVirtualization() error {
+	supported, known, err := host.VirtualizationSupported()
+	if err != nil {
+		return err
+	}
+	if known && !supported {
+		return fmt.Errorf("hardware virtualization (VT-x/AMD-V) is not available, enable it in the BIOS/UEFI settings of your computer")
+	}
+
+	return nil
+}
+
+// checkAvailableMemory refuses to start a VM of vmem megabytes which would not fit in memory
+func checkAvailableMemory(vmem int) error {
+	available, known, err := host.AvailableMemory()
+	if err != nil || !known {
+		return err
+	}
+
+	if needed := uint64(vmem) * 1024 * 1024; available < needed {
+		return fmt.Errorf(
+			"only %d MB of memory are available and vmem is %d MB, close some applications or lower vmem in config.yml",
+			available/1024/1024,
+			vmem,
+		)
+	}
+
+	return nil
+}
@@ -3,73 +3,147 @@ package providers
 import (
 	"context"
 	"denver/pkg/ssh"
+	"denver/structs"
 	"log"
+	"reflect"
 	"strings"
 	"time"
 )
 
+const (
+	minProbeInterval = 250 * time.Millisecond
+	maxProbeInterval = 8 * time.Second
+)
+
 // Probe struct allow us to get VM status
 type Probe struct {
 	ssh    *ssh.SSH
-	ticker *time.Ticker
 	ctx    context.Context
+	checks []healthCheck
+	// savedKnown is set once the saved state of the VM has been queried since it went down
+	savedKnown bool
 }
 
-// NewProbe returns a pointer to Probe
-func NewProbe(ctx context.Context, ssh *ssh.SSH) *Probe {
-	return &Probe{
-		ssh: ssh,
-		ctx: ctx,
+// NewProbe returns a pointer to Probe, the health checks are validated
+func NewProbe(ctx context.Context, ssh *ssh.SSH, healthChecks []structs.HealthCheck) (*Probe, error) {
+	checks, err := newHealthChecks(healthChecks)
+	if err != nil {
+		return nil, err
 	}
+
+	return &Probe{
+		ssh:    ssh,
+		ctx:    ctx,
+		checks: checks,
+	}, nil
+}
+
+// backgrounder is implemented by the providers whose probe commands can be told apart from
+// the ones of the command being run
+type backgrounder interface {
+	background() VMProvider
 }
 
-// Start polling a VM instance
+// Start polling a VM instance, every minProbeInterval while its state changes or someone waits
+// for it to change, the interval doubles up to maxProbeInterval as long as it is stable
 func (s *Probe) Start(vmProvider VMProvider) error {
-	s.ticker = time.NewTicker(250 * time.Millisecond)
+	if b, ok := vmProvider.(backgrounder); ok {
+		vmProvider = b.background()
+	}
 
 	if err := s.probe(vmProvider); err != nil {
 		return err
 	}
 
 	go func() {
-		defer s.ticker.Stop()
+		states := vmProvider.StateStore()
+		interval := minProbeInterval
+		timer := time.NewTimer(interval)
+		defer timer.Stop()
 
 		for {
 			select {
-			case <-s.ticker.C:
-				_ = s.probe(vmProvider)
+			case <-timer.C:
+			case <-states.Subscribed():
+				// Someone has started waiting, probe now rather than after the backed off interval
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
 			case <-s.ctx.Done():
 				return
 			}
+
+			before := states.Get()
+			_ = s.probe(vmProvider)
+			changed := !reflect.DeepEqual(before, states.Get())
+
+			interval = nextProbeInterval(interval, changed || states.HasSubscribers())
+			timer.Reset(interval)
 		}
 	}()
 
 	return nil
 }
 
+// nextProbeInterval backs off while the state is stable, and starts over during transitions
+func nextProbeInterval(interval time.Duration, transition bool) time.Duration {
+	if transition {
+		return minProbeInterval
+	}
+
+	if interval *= 2; interval > maxProbeInterval {
+		return maxProbeInterval
+	}
+
+	return interval
+}
+
 func (s *Probe) probe(vmProvider VMProvider) (err error) {
 	vmState := NewState()
-	defer func() {
-		err := vmProvider.setState(vmState)
-		if err != nil {
-			log.Println(err)
-		}
-	}()
 
-	// Check is VM is alive
+	// Check is VM is alive, a failed query tells nothing about the VM so the last state is kept
 	if vmState.Live, err = vmProvider.checkIfRunning(); err != nil {
+		log.Printf("Unable to query the state of the VM: %s", err)
 		return
 	}
 
 	if vmState.Live != true {
-		return
+		if err = s.checkSaved(vmProvider, vmState); err != nil {
+			log.Printf("Unable to query the saved state of the VM: %s", err)
+			return
+		}
+	} else {
+		s.savedKnown = false
+
+		// Check if all systems are ready on the Virtual Machine
+		err = s.checkAllSystemsReady(vmState)
+	}
+
+	if setErr := vmProvider.setState(vmState); setErr != nil {
+		log.Println(setErr)
 	}
 
-	// Check if all systems are ready on the Virtual Machine
-	if err = s.checkAllSystemsReady(vmState); err != nil {
+	return
+}
+
+// checkSaved queries whether the VM has been suspended once it has gone down, it can't change while
+// the VM is off so the last state is kept until it runs again
+func (s *Probe) checkSaved(vmProvider VMProvider, vmState *State) (err error) {
+	if s.savedKnown {
+		vmState.Saved = vmProvider.GetState().Saved
 		return
 	}
 
+	if suspender, ok := vmProvider.(Suspender); ok {
+		if vmState.Saved, err = suspender.IsSaved(); err != nil {
+			return
+		}
+	}
+	s.savedKnown = true
+
 	return
 }
 
@@ -81,7 +155,11 @@ func (s *Probe) checkAllSystemsReady(VMState *State) (err error) {
 	}
 
 	cmdReturn := strings.TrimSpace(out)
-	VMState.AllSystemsReady = cmdReturn == "OK"
+	if cmdReturn != "OK" {
+		return
+	}
+
+	VMState.Checks, VMState.AllSystemsReady = runHealthChecks(s.ssh, s.checks)
 
 	return
 }
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeBacksOffWhileTheStateIsStable(t *testing.T) {
+	assert := assert.New(t)
+
+	interval := minProbeInterval
+	var intervals []time.Duration
+	for i := 0; i < 7; i++ {
+		interval = nextProbeInterval(interval, false)
+		intervals = append(intervals, interval)
+	}
+
+	assert.Equal([]time.Duration{
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		8 * time.Second,
+		8 * time.Second,
+	}, intervals)
+}
+
+func TestProbeSpeedsUpDuringTransitions(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(minProbeInterval, nextProbeInterval(maxProbeInterval, true))
+}
+
+func TestProbeQueriesTheSavedStateOncePerStop(t *testing.T) {
+	assert := assert.New(t)
+	fake := NewFake(State{Saved: true})
+	probe := &Probe{}
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(probe.probe(fake))
+	}
+	assert.True(fake.GetState().Saved)
+	assert.Equal([]string{"IsSaved"}, fake.Calls())
+}
+
+type unreachableProvider struct {
+	*Fake
+}
+
+func (u unreachableProvider) checkIfRunning() (bool, error) {
+	return false, errors.New("VBoxManage: error: Failed to create the VirtualBox object")
+}
+
+func TestProbeKeepsTheStateWhenTheQueryFails(t *testing.T) {
+	assert := assert.New(t)
+	fake := NewFake(State{Live: true, OsReady: true, AllSystemsReady: true})
+	probe := &Probe{}
+
+	// The VM has not gone down
+	assert.Error(probe.probe(unreachableProvider{fake}))
+	assert.Equal(&State{Live: true, OsReady: true, AllSystemsReady: true}, fake.GetState())
+}
+
+func TestProbeKeepsTheStateWhenTheSavedQueryFails(t *testing.T) {
+	assert := assert.New(t)
+	fake := NewFake(State{Saved: true}).Fail("IsSaved", errors.New("VBoxManage: error: Failed to create the VirtualBox object"))
+	probe := &Probe{}
+
+	assert.Error(probe.probe(fake))
+	assert.True(fake.GetState().Saved)
+	assert.False(probe.savedKnown)
+}
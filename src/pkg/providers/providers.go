@@ -16,33 +16,41 @@ type VMProvider interface {
 	Init() error
 	Start() error
 	Stop() error
+	PowerOff() error
 	Unregister() error
 	Update() (bool, error)
 	CheckIsUpdated() (bool, error)
 	GetState() *State
+	StateStore() *StateStore
+	AddAction(LifecyclePoint, func() error)
 	AddPostStartAction(func() error)
 	AddPreStopAction(func() error)
 	checkIfRunning() (bool, error)
 	setState(state *State) error
 }
 
-// State : Health of the virtual machine
+// State : Health of the virtual machine, Saved is set when the VM is off because it has been suspended.
+// Checks holds the last results of the configured health checks, they are run once the OS is ready.
 type State struct {
-	Live            bool
-	OsReady         bool
-	AllSystemsReady bool
+	Live            bool          `json:"live" yaml:"live"`
+	Saved           bool          `json:"saved" yaml:"saved"`
+	OsReady         bool          `json:"osReady" yaml:"osReady"`
+	AllSystemsReady bool          `json:"allSystemsReady" yaml:"allSystemsReady"`
+	Checks          []CheckResult `json:"checks,omitempty" yaml:"checks,omitempty"`
 }
 
 // VMUpdater interface complements VMProvider and allow update VM components
 type VMUpdater interface {
 	CheckIsUpdated() (bool, error)
 	Update() error
+	GetLocalManifest() (virtualbox.Manifest, error)
 }
 
 // NewState returns a pointer to state
 func NewState() *State {
 	return &State{
 		Live:            false,
+		Saved:           false,
 		OsReady:         false,
 		AllSystemsReady: false,
 	}
@@ -53,23 +61,46 @@ func GetVMProvider(
 	ctx context.Context,
 	provider structs.Provider,
 	instance *structs.InstanceConf,
-	userDataSize int,
 	channel string,
 	rbiurl string,
 	workingDirectory string,
+	exec executor.Executor,
 ) (VMProvider, error) {
+	_, dryRun := exec.(*executor.DryRunExecutor)
+
 	switch provider.Hypervisor {
 	case TypeVirtualbox:
-		updater, boxPath, err := getVMUpdater(ctx, workingDirectory, channel, rbiurl, provider.Hypervisor)
+		updater, boxPath, err := getVMUpdater(ctx, workingDirectory, instance.Store, channel, rbiurl, provider.Hypervisor)
+		if err != nil {
+			return nil, err
+		}
+		if dryRun {
+			updater = newDryRunUpdater(updater)
+		}
+		vb := newVirtualBox(
+			provider,
+			instance,
+			boxPath,
+			exec,
+			updater,
+			workingDirectory,
+		)
+		vb.dryRun = dryRun
+		return vb, nil
+	case TypeQemu:
+		// QMP commands go through a socket, they can't be previewed
+		if dryRun {
+			return nil, fmt.Errorf("the %s provider does not support dry-run", provider.Hypervisor)
+		}
+		updater, boxPath, err := getVMUpdater(ctx, workingDirectory, instance.Store, channel, rbiurl, provider.Hypervisor)
 		if err != nil {
 			return nil, err
 		}
-		return newVirtualBox(
+		return newQemu(
 			provider,
 			instance,
 			boxPath,
-			executor.NewExecutor(),
-			userDataSize,
+			exec,
 			updater,
 			workingDirectory,
 		), nil
@@ -78,22 +109,48 @@ func GetVMProvider(
 	return nil, fmt.Errorf("invalid provider %s", provider.Hypervisor)
 }
 
-func getVMUpdater(ctx context.Context, workingDirectory, channel, rbiurl, hypervisor string) (VMUpdater, string, error) {
+func getVMUpdater(ctx context.Context, workingDirectory, store, channel, rbiurl, hypervisor string) (VMUpdater, string, error) {
 	switch hypervisor {
 	case TypeVirtualbox:
-		relBoxPath := filepath.Join("store", channel, "box.vdi")
+		relBoxPath := filepath.Join(store, channel, "box.vdi")
 		absBoxPath := filepath.Join(workingDirectory, relBoxPath)
 		return virtualbox.NewUpdater(
 			ctx,
 			workingDirectory,
 			fmt.Sprintf("%s/%s/virtualbox/manifest.json", rbiurl, channel),
-			filepath.Join("store", channel, "manifest.json"),
+			filepath.Join(store, channel, "manifest.json"),
 			fmt.Sprintf("%s/%s/virtualbox/box.vdi.bz2", rbiurl, channel),
 			relBoxPath,
 			&http.HTTP{},
 			compressor.NewMultiCompressor(),
 		), absBoxPath, nil
+	case TypeQemu:
+		// The box updater does not depend on the hypervisor, only the artifacts differ
+		relBoxPath := filepath.Join(store, channel, "qemu", "box.qcow2")
+		absBoxPath := filepath.Join(workingDirectory, relBoxPath)
+		return virtualbox.NewUpdater(
+			ctx,
+			workingDirectory,
+			fmt.Sprintf("%s/%s/qemu/manifest.json", rbiurl, channel),
+			filepath.Join(store, channel, "qemu", "manifest.json"),
+			fmt.Sprintf("%s/%s/qemu/box.qcow2.bz2", rbiurl, channel),
+			relBoxPath,
+			&http.HTTP{},
+			compressor.NewMultiCompressor(),
+		), absBoxPath, nil
 	}
 
 	return nil, "", fmt.Errorf("invalid provider %s", hypervisor)
 }
+
+// userDataPath returns the configured userdata disk or the default one inside the instance store
+func userDataPath(workingDirectory string, instance *structs.InstanceConf, defaultFile string) string {
+	if instance.Userdata == "" {
+		return filepath.Join(workingDirectory, instance.Store, defaultFile)
+	}
+	if filepath.IsAbs(instance.Userdata) {
+		return instance.Userdata
+	}
+
+	return filepath.Join(workingDirectory, instance.Userdata)
+}
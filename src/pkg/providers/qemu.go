@@ -0,0 +1,416 @@
+package providers
+
+import (
+	"denver/pkg/providers/qemu"
+	"denver/pkg/providers/virtualbox"
+	"denver/pkg/util"
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// TypeQemu used to discriminate between other implementations
+var TypeQemu = "qemu"
+
+// Qemu implementation
+type Qemu struct {
+	provider     structs.Provider
+	instance     *structs.InstanceConf
+	boxPath      string
+	storePath    string
+	userData     string
+	userDataSize int
+	executor     executor.Executor
+	qmp          *qemu.QMP
+	states       *StateStore
+	updater      VMUpdater
+
+	*lifecycle
+}
+
+func newQemu(
+	provider structs.Provider,
+	instance *structs.InstanceConf,
+	boxPath string,
+	executor executor.Executor,
+	updater VMUpdater,
+	workingDirectory string,
+) *Qemu {
+	storePath := filepath.Join(workingDirectory, instance.Store, "qemu")
+	return &Qemu{
+		provider:     provider,
+		instance:     instance,
+		boxPath:      boxPath,
+		storePath:    storePath,
+		userData:     userDataPath(workingDirectory, instance, filepath.Join("qemu", "userdata.qcow2")),
+		userDataSize: instance.Userdatasize,
+		executor:     executor,
+		qmp:          qemu.NewQMP(filepath.Join(storePath, "qmp.sock")),
+		states:       NewStateStore(),
+		updater:      updater,
+		lifecycle:    newLifecycle(),
+	}
+}
+
+// Init VM
+func (q *Qemu) Init() (err error) {
+	_, err = q.Update()
+	if err != nil {
+		return
+	}
+
+	exists, err := q.checkIfExists()
+	if err != nil {
+		return
+	}
+
+	if exists {
+		return fmt.Errorf("%s already exists", q.instance.Name)
+	}
+	return q.init()
+}
+
+// Start VM
+func (q *Qemu) Start() (err error) {
+	if exists, err := q.checkIfExists(); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("%s does not exists", q.instance.Name)
+	}
+
+	if isRunning, err := q.checkIfRunning(); err != nil {
+		return err
+	} else if isRunning {
+		return fmt.Errorf("%s is running", q.instance.Name)
+	}
+
+	if err = q.run(PreStart); err != nil {
+		return
+	}
+
+	// The tap interface does not survive a reboot of the host
+	if exists, err := q.tapExists(); err != nil {
+		return err
+	} else if !exists {
+		if err = q.createHostOnlyNetwork(q.instance.Localip); err != nil {
+			return err
+		}
+	}
+
+	cmd := []string{
+		"qemu-system-x86_64",
+		"-name", q.instance.Name,
+		"-machine", "q35,accel=kvm",
+		"-cpu", "host",
+		"-smp", fmt.Sprintf("%d", q.instance.Vcpu),
+		"-m", fmt.Sprintf("%d", q.instance.Vmem),
+		"-drive", fmt.Sprintf("file=%s,if=virtio,format=qcow2,discard=unmap", q.rootDisk()),
+		"-drive", fmt.Sprintf("file=%s,if=virtio,format=qcow2,discard=unmap", q.userData),
+		"-netdev", "user,id=nat",
+		"-device", "virtio-net-pci,netdev=nat",
+		"-netdev", fmt.Sprintf("tap,id=hostonly,ifname=%s,script=no,downscript=no", q.tapName()),
+		"-device", "virtio-net-pci,netdev=hostonly",
+		"-display", "none",
+		"-qmp", fmt.Sprintf("unix:%s,server,nowait", q.qmpSocket()),
+		"-pidfile", q.pidFile(),
+		"-daemonize",
+	}
+	q.expect(PostStart)
+	if _, err = q.executor.Execute(cmd); err != nil {
+		q.unexpect(PostStart)
+	}
+	return
+}
+
+// Stop VM
+func (q *Qemu) Stop() error {
+	if exists, err := q.checkIfExists(); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("%s does not exists", q.instance.Name)
+	}
+
+	if isRunning, err := q.checkIfRunning(); err != nil {
+		return err
+	} else if !isRunning {
+		return fmt.Errorf("%s is not running", q.instance.Name)
+	}
+
+	if err := q.run(PreStop); err != nil {
+		return err
+	}
+
+	return q.stopWith("system_powerdown")
+}
+
+// PowerOff kills the VM, the guest is not shut down and the pre-stop actions are not run
+func (q *Qemu) PowerOff() error {
+	if exists, err := q.checkIfExists(); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("%s does not exists", q.instance.Name)
+	}
+
+	if isRunning, err := q.checkIfRunning(); err != nil {
+		return err
+	} else if !isRunning {
+		return fmt.Errorf("%s is not running", q.instance.Name)
+	}
+
+	return q.stopWith("quit")
+}
+
+// stopWith sends the QMP command stopping the VM, the post-stop actions run once it is down
+func (q *Qemu) stopWith(command string) error {
+	q.expect(PostStop)
+	if _, err := q.qmp.Execute(command, nil); err != nil {
+		q.unexpect(PostStop)
+		return err
+	}
+
+	return nil
+}
+
+// GetState VM
+func (q *Qemu) GetState() *State {
+	return q.states.Get()
+}
+
+// StateStore VM
+func (q *Qemu) StateStore() *StateStore {
+	return q.states
+}
+
+// background returns the view of the VM used by the probe, its commands are left out of the recordings
+func (q *Qemu) background() VMProvider {
+	view := *q
+	view.executor = executor.NewBackgroundExecutor(q.executor)
+	return &view
+}
+
+// CheckIsUpdated VM
+func (q *Qemu) CheckIsUpdated() (isUpToDate bool, err error) {
+	return q.updater.CheckIsUpdated()
+}
+
+// Update VM
+func (q *Qemu) Update() (updated bool, err error) {
+	isUpToDate, err := q.updater.CheckIsUpdated()
+	if err != nil || isUpToDate {
+		return
+	}
+
+	if err = q.run(PreUpdate); err != nil {
+		return
+	}
+
+	err = q.unregisterIfExists()
+	if err != nil {
+		return
+	}
+
+	err = q.updater.Update()
+	if err != nil {
+		return
+	}
+
+	if err = q.init(); err != nil {
+		return
+	}
+
+	return true, q.run(PostUpdate)
+}
+
+func (q *Qemu) unregisterIfExists() (err error) {
+	exists, err := q.checkIfExists()
+	if err != nil {
+		return
+	}
+	if !exists {
+		return
+	}
+	return q.Unregister()
+}
+
+// Unregister a VM, the userdata disk is kept
+func (q *Qemu) Unregister() (err error) {
+	cmd := []string{
+		"ip", "link", "delete", q.tapName(),
+	}
+	// The tap interface is already gone after a reboot of the host
+	if _, err = q.executor.Execute(cmd); err != nil && !isMissingDevice(err) {
+		return
+	}
+
+	for _, file := range []string{q.rootDisk(), q.pidFile(), q.qmpSocket()} {
+		if err = os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return
+		}
+	}
+
+	return nil
+}
+
+func (q *Qemu) setState(state *State) (err error) {
+	return q.publish(q.states, *state)
+}
+
+func (q *Qemu) init() (err error) {
+	if err := os.MkdirAll(q.storePath, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := q.createRootDisk(); err != nil {
+		return err
+	}
+
+	fileExist, _ := util.Exists(q.userData)
+	if !fileExist {
+		if err := q.createUserData(fmt.Sprintf("%dM", (q.userDataSize * 1024))); err != nil {
+			return err
+		}
+	}
+
+	return q.createHostOnlyNetwork(q.instance.Localip)
+}
+
+// The root disk is a copy-on-write overlay, the RBI itself is never written
+func (q *Qemu) createRootDisk() error {
+	cmd := []string{
+		"qemu-img", "create",
+		"-f", "qcow2",
+		"-F", "qcow2",
+		"-b", q.boxPath,
+		q.rootDisk(),
+	}
+	_, err := q.executor.Execute(cmd)
+	return err
+}
+
+func (q *Qemu) createUserData(size string) error {
+	cmd := []string{
+		"qemu-img", "create",
+		"-f", "qcow2",
+		q.userData,
+		size,
+	}
+	_, err := q.executor.Execute(cmd)
+	return err
+}
+
+// The tap interface plays the role of the VirtualBox host-only network,
+// managing it requires the CAP_NET_ADMIN capability on the host
+func (q *Qemu) createHostOnlyNetwork(localip string) error {
+	current, err := user.Current()
+	if err != nil {
+		return err
+	}
+
+	localiparray := strings.Split(localip, ".")
+	if len(localiparray) != 4 {
+		return fmt.Errorf("invalid local ip %s", localip)
+	}
+	hostip := localiparray[0] + "." + localiparray[1] + "." + localiparray[2] + ".1"
+
+	cmds := [][]string{
+		{"ip", "tuntap", "add", "dev", q.tapName(), "mode", "tap", "user", current.Username},
+		{"ip", "addr", "add", hostip + "/24", "dev", q.tapName()},
+		{"ip", "link", "set", q.tapName(), "up"},
+	}
+	for _, cmd := range cmds {
+		if _, err := q.executor.Execute(cmd); err != nil {
+			return fmt.Errorf("unable to configure interface %s: %s", q.tapName(), err)
+		}
+	}
+
+	return nil
+}
+
+func (q *Qemu) tapExists() (bool, error) {
+	cmd := []string{
+		"ip", "link", "show", "dev", q.tapName(),
+	}
+	if _, err := q.executor.Execute(cmd); err != nil {
+		if isMissingDevice(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// isMissingDevice tells whether ip failed because the interface does not exist
+func isMissingDevice(err error) bool {
+	e, ok := err.(*executor.Error)
+	if !ok {
+		return false
+	}
+
+	return strings.Contains(e.Stderr, "does not exist") || strings.Contains(e.Stderr, "Cannot find device")
+}
+
+func (q *Qemu) checkIfRunning() (bool, error) {
+	content, err := ioutil.ReadFile(q.pidFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return false, fmt.Errorf("invalid pid file %s: %s", q.pidFile(), err)
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false, nil
+	}
+
+	// QEMU removes its pid file on a clean shutdown, but not when it is killed
+	return process.Signal(syscall.Signal(0)) == nil, nil
+}
+
+func (q *Qemu) checkIfExists() (bool, error) {
+	return util.Exists(q.rootDisk())
+}
+
+func (q *Qemu) rootDisk() string {
+	return filepath.Join(q.storePath, "root.qcow2")
+}
+
+func (q *Qemu) pidFile() string {
+	return filepath.Join(q.storePath, "qemu.pid")
+}
+
+func (q *Qemu) qmpSocket() string {
+	return filepath.Join(q.storePath, "qmp.sock")
+}
+
+// Interface names are limited to 15 characters
+func (q *Qemu) tapName() string {
+	name := fmt.Sprintf("dnv-%s", q.instance.Name)
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	return name
+}
+
+// UserDataPath returns the path of the userdata disk
+func (q *Qemu) UserDataPath() string {
+	return q.userData
+}
+
+// GetRBIManifest returns the manifest of the installed Root Base Image
+func (q *Qemu) GetRBIManifest() (virtualbox.Manifest, error) {
+	return q.updater.GetLocalManifest()
+}
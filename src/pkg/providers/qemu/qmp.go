@@ -0,0 +1,97 @@
+package qemu
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// QMP talks to a running QEMU instance through its QEMU Machine Protocol socket
+type QMP struct {
+	socket  string
+	timeout time.Duration
+}
+
+type qmpCommand struct {
+	Execute   string                 `json:"execute"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+type qmpResponse struct {
+	Return json.RawMessage `json:"return"`
+	Error  *struct {
+		Class string `json:"class"`
+		Desc  string `json:"desc"`
+	} `json:"error"`
+	Event string `json:"event"`
+}
+
+// NewQMP returns a pointer to QMP
+func NewQMP(socket string) *QMP {
+	return &QMP{
+		socket:  socket,
+		timeout: 5 * time.Second,
+	}
+}
+
+// Execute sends a single command to QEMU and returns the raw content of its answer
+func (q *QMP) Execute(command string, arguments map[string]interface{}) (ret json.RawMessage, err error) {
+	conn, err := net.DialTimeout("unix", q.socket, q.timeout)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(q.timeout)); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(conn)
+
+	// QEMU greets every new client, the greeting has to be consumed first
+	if _, err = reader.ReadBytes('\n'); err != nil {
+		return
+	}
+
+	if _, err = q.send(conn, reader, qmpCommand{Execute: "qmp_capabilities"}); err != nil {
+		return
+	}
+
+	return q.send(conn, reader, qmpCommand{Execute: command, Arguments: arguments})
+}
+
+func (q *QMP) send(conn net.Conn, reader *bufio.Reader, command qmpCommand) (ret json.RawMessage, err error) {
+	body, err := json.Marshal(command)
+	if err != nil {
+		return
+	}
+
+	if _, err = conn.Write(append(body, '\n')); err != nil {
+		return
+	}
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		var response qmpResponse
+		if err = json.Unmarshal(line, &response); err != nil {
+			return nil, err
+		}
+
+		// Asynchronous events may be interleaved with the answer we are waiting for
+		if response.Event != "" {
+			continue
+		}
+
+		if response.Error != nil {
+			return nil, fmt.Errorf("qmp %s: %s", command.Execute, response.Error.Desc)
+		}
+
+		return response.Return, nil
+	}
+}
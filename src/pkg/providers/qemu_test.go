@@ -0,0 +1,227 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"denver/pkg/providers/virtualbox"
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upToDateUpdater struct{}
+
+func (u *upToDateUpdater) CheckIsUpdated() (bool, error) { return true, nil }
+func (u *upToDateUpdater) Update() error                 { return nil }
+func (u *upToDateUpdater) GetLocalManifest() (virtualbox.Manifest, error) {
+	return virtualbox.Manifest{Version: "1.0.0"}, nil
+}
+
+// Every stub appends its command line to calls.log, qemu-img creates the
+// requested disks, qemu-system-x86_64 writes the pid of the test process and
+// ip does not find the tap interface when STUB_NO_TAP is set
+var qemuStubs = map[string]string{
+	"qemu-img": `#!/bin/sh
+echo "qemu-img $@" >> "$STUB_LOG"
+for a in "$@"; do case "$a" in *root.qcow2|*userdata.qcow2) touch "$a";; esac; done
+`,
+	"qemu-system-x86_64": `#!/bin/sh
+echo "qemu-system-x86_64 $@" >> "$STUB_LOG"
+while [ $# -gt 0 ]; do [ "$1" = "-pidfile" ] && echo "$STUB_PID" > "$2"; shift; done
+`,
+	"ip": `#!/bin/sh
+echo "ip $@" >> "$STUB_LOG"
+if [ -n "$STUB_NO_TAP" ]; then
+	case "$2" in
+	show) echo "Device \"$4\" does not exist." >&2; exit 1;;
+	delete) echo "Cannot find device \"$3\"" >&2; exit 1;;
+	esac
+fi
+`,
+}
+
+func newStubbedQemu(t *testing.T) (q *Qemu, dir string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "qemu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bin := filepath.Join(dir, "bin")
+	if err = os.MkdirAll(bin, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range qemuStubs {
+		if err = ioutil.WriteFile(filepath.Join(bin, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := os.Getenv("PATH")
+	_ = os.Setenv("PATH", fmt.Sprintf("%s%c%s", bin, os.PathListSeparator, path))
+	_ = os.Setenv("STUB_LOG", filepath.Join(dir, "calls.log"))
+	_ = os.Setenv("STUB_PID", fmt.Sprintf("%d", os.Getpid()))
+
+	q = newQemu(
+		structs.Provider{Name: "local-qemu", Hypervisor: TypeQemu},
+		&structs.InstanceConf{Name: "denver", Vcpu: 2, Vmem: 2048, Localip: "10.10.10.10", Userdatasize: 32, Store: "store"},
+		filepath.Join(dir, "store", "stable", "qemu", "box.qcow2"),
+		executor.NewDefaultExecutor(context.Background()),
+		&upToDateUpdater{},
+		dir,
+	)
+
+	return q, dir, func() {
+		_ = os.Setenv("PATH", path)
+		_ = os.Unsetenv("STUB_NO_TAP")
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func stubCalls(t *testing.T, dir string) []string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, "calls.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestQemuInitCreatesDisksAndNetwork(t *testing.T) {
+	assert := assert.New(t)
+	q, dir, cleanup := newStubbedQemu(t)
+	defer cleanup()
+
+	assert.NoError(q.Init())
+
+	calls := stubCalls(t, dir)
+	assert.Len(calls, 5)
+	assert.Equal(fmt.Sprintf("qemu-img create -f qcow2 -F qcow2 -b %s %s", q.boxPath, q.rootDisk()), calls[0])
+	assert.Equal(fmt.Sprintf("qemu-img create -f qcow2 %s 32768M", q.userData), calls[1])
+	assert.True(strings.HasPrefix(calls[2], "ip tuntap add dev dnv-denver mode tap"))
+	assert.Equal("ip addr add 10.10.10.1/24 dev dnv-denver", calls[3])
+	assert.Equal("ip link set dnv-denver up", calls[4])
+
+	assert.EqualError(q.Init(), "denver already exists")
+}
+
+func TestQemuStartRunsTheVM(t *testing.T) {
+	assert := assert.New(t)
+	q, dir, cleanup := newStubbedQemu(t)
+	defer cleanup()
+
+	assert.EqualError(q.Start(), "denver does not exists")
+
+	assert.NoError(q.init())
+	assert.NoError(q.Start())
+
+	running, err := q.checkIfRunning()
+	assert.NoError(err)
+	assert.True(running)
+
+	calls := stubCalls(t, dir)
+	start := calls[len(calls)-1]
+	assert.Contains(start, "-smp 2 -m 2048")
+	assert.Contains(start, "ifname=dnv-denver")
+	assert.Contains(start, "-daemonize")
+
+	assert.EqualError(q.Start(), "denver is running")
+}
+
+func TestQemuStartCreatesTheMissingTap(t *testing.T) {
+	assert := assert.New(t)
+	q, dir, cleanup := newStubbedQemu(t)
+	defer cleanup()
+
+	assert.NoError(q.init())
+	// The host has been rebooted since the init
+	_ = os.Setenv("STUB_NO_TAP", "1")
+	assert.NoError(q.Start())
+
+	calls := stubCalls(t, dir)
+	calls = calls[len(calls)-5:]
+	assert.Equal("ip link show dev dnv-denver", calls[0])
+	assert.True(strings.HasPrefix(calls[1], "ip tuntap add dev dnv-denver mode tap"))
+	assert.Equal("ip addr add 10.10.10.1/24 dev dnv-denver", calls[2])
+	assert.Equal("ip link set dnv-denver up", calls[3])
+	assert.True(strings.HasPrefix(calls[4], "qemu-system-x86_64"))
+}
+
+func TestQemuStopSendsAPowerdownThroughQMP(t *testing.T) {
+	assert := assert.New(t)
+	q, _, cleanup := newStubbedQemu(t)
+	defer cleanup()
+
+	assert.NoError(q.init())
+	assert.NoError(q.Start())
+
+	listener, err := net.Listen("unix", q.qmpSocket())
+	assert.NoError(err)
+	defer listener.Close()
+
+	commands := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = fmt.Fprintln(conn, `{"QMP": {"version": {}, "capabilities": []}}`)
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var command qmpTestCommand
+			_ = json.Unmarshal(scanner.Bytes(), &command)
+			commands <- command.Execute
+			_, _ = fmt.Fprintln(conn, `{"return": {}}`)
+		}
+	}()
+
+	assert.NoError(q.Stop())
+	assert.Equal("qmp_capabilities", <-commands)
+	assert.Equal("system_powerdown", <-commands)
+}
+
+func TestQemuUnregisterKeepsUserData(t *testing.T) {
+	assert := assert.New(t)
+	q, dir, cleanup := newStubbedQemu(t)
+	defer cleanup()
+
+	assert.NoError(q.init())
+	assert.NoError(q.Unregister())
+
+	exists, err := q.checkIfExists()
+	assert.NoError(err)
+	assert.False(exists)
+
+	_, err = os.Stat(q.userData)
+	assert.NoError(err)
+
+	calls := stubCalls(t, dir)
+	assert.Equal("ip link delete dnv-denver", calls[len(calls)-1])
+}
+
+func TestQemuUnregisterWithoutTap(t *testing.T) {
+	assert := assert.New(t)
+	q, _, cleanup := newStubbedQemu(t)
+	defer cleanup()
+
+	assert.NoError(q.init())
+	_ = os.Setenv("STUB_NO_TAP", "1")
+	assert.NoError(q.Unregister())
+
+	exists, err := q.checkIfExists()
+	assert.NoError(err)
+	assert.False(exists)
+}
+
+type qmpTestCommand struct {
+	Execute string `json:"execute"`
+}
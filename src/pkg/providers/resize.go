@@ -0,0 +1,13 @@
+package providers
+
+// Resizer is implemented by the providers able to change the resources of an existing VM
+type Resizer interface {
+	Resize() ([]ResourceChange, error)
+}
+
+// ResourceChange describes a VM resource drifting from the configuration
+type ResourceChange struct {
+	Name string
+	From string
+	To   string
+}
@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshotter is implemented by the providers able to snapshot their VM
+type Snapshotter interface {
+	SaveSnapshot(name string) error
+	ListSnapshots() ([]Snapshot, error)
+	RestoreSnapshot(name string, force bool) error
+	DeleteSnapshot(name string) error
+}
+
+// Snapshot describes a saved state of the VM
+type Snapshot struct {
+	Name       string    `json:"name" yaml:"name"`
+	Date       time.Time `json:"date" yaml:"date"`
+	RBIVersion string    `json:"rbiVersion" yaml:"rbiVersion"`
+}
+
+// snapshotIndex keeps the metadata the hypervisors do not give back
+type snapshotIndex struct {
+	path string
+}
+
+func newSnapshotIndex(storePath string) *snapshotIndex {
+	return &snapshotIndex{
+		path: filepath.Join(storePath, "snapshots.json"),
+	}
+}
+
+func (s *snapshotIndex) read() (snapshots map[string]Snapshot, err error) {
+	snapshots = map[string]Snapshot{}
+
+	body, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return snapshots, nil
+		}
+		return
+	}
+
+	err = json.Unmarshal(body, &snapshots)
+	return
+}
+
+func (s *snapshotIndex) write(snapshots map[string]Snapshot) (err error) {
+	body, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+		return
+	}
+
+	return ioutil.WriteFile(s.path, body, 0644)
+}
+
+func (s *snapshotIndex) add(snapshot Snapshot) (err error) {
+	snapshots, err := s.read()
+	if err != nil {
+		return
+	}
+
+	snapshots[snapshot.Name] = snapshot
+	return s.write(snapshots)
+}
+
+func (s *snapshotIndex) remove(name string) (err error) {
+	snapshots, err := s.read()
+	if err != nil {
+		return
+	}
+
+	delete(snapshots, name)
+	return s.write(snapshots)
+}
+
+// clear forgets all the snapshots, once the VM is gone
+func (s *snapshotIndex) clear() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
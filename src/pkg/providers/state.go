@@ -0,0 +1,152 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StateEventType tells which transition of the state of a VM an event reports
+type StateEventType string
+
+const (
+	// EventPoweredOn is published when the VM starts running
+	EventPoweredOn StateEventType = "powered-on"
+	// EventSSHUp is published when SSH answers on the VM
+	EventSSHUp StateEventType = "ssh-up"
+	// EventSystemsReady is published when all the systems of the VM are up
+	EventSystemsReady StateEventType = "systems-ready"
+	// EventWentDown is published when the VM stops running, State.Saved tells whether it has been suspended
+	EventWentDown StateEventType = "went-down"
+)
+
+// Events are dropped for a subscriber which lags this far behind
+const stateEventsBuffer = 32
+
+// StateEvent is a transition of the state of a VM, State is the state it led to
+type StateEvent struct {
+	Type  StateEventType `json:"type" yaml:"type"`
+	State State          `json:"state" yaml:"state"`
+	Time  time.Time      `json:"time" yaml:"time"`
+}
+
+// StateStore holds the state of a VM, it can be read and subscribed to from any goroutine
+type StateStore struct {
+	mutex       sync.Mutex
+	state       State
+	subscribers map[int]chan StateEvent
+	next        int
+	subscribed  chan struct{}
+}
+
+// NewStateStore returns a pointer to a StateStore holding a stopped VM
+func NewStateStore() *StateStore {
+	return &StateStore{
+		state:       *NewState(),
+		subscribers: map[int]chan StateEvent{},
+		subscribed:  make(chan struct{}, 1),
+	}
+}
+
+// Get returns a copy of the current state
+func (s *StateStore) Get() *State {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	state := s.state
+	return &state
+}
+
+// Set replaces the state and publishes the transitions to the subscribers, which are returned
+func (s *StateStore) Set(state State) []StateEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	events := stateEvents(s.state, state, time.Now())
+	s.state = state
+	for _, event := range events {
+		for _, subscriber := range s.subscribers {
+			select {
+			case subscriber <- event:
+			default:
+			}
+		}
+	}
+
+	return events
+}
+
+// Subscribe returns the channel receiving the next events and the function ending the subscription
+func (s *StateStore) Subscribe() (<-chan StateEvent, func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := s.next
+	s.next++
+	events := make(chan StateEvent, stateEventsBuffer)
+	s.subscribers[id] = events
+	select {
+	case s.subscribed <- struct{}{}:
+	default:
+	}
+
+	return events, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+// Subscribed receives when a subscription starts, someone is waiting for the next transitions
+func (s *StateStore) Subscribed() <-chan struct{} {
+	return s.subscribed
+}
+
+// HasSubscribers tells whether someone is waiting for the next transitions
+func (s *StateStore) HasSubscribers() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.subscribers) > 0
+}
+
+// Wait blocks until the state satisfies until, it returns false if ctx is done first
+func (s *StateStore) Wait(ctx context.Context, until func(*State) bool) bool {
+	// Subscribing first, a transition happening before the check below can't be missed
+	events, unsubscribe := s.Subscribe()
+	defer unsubscribe()
+
+	if until(s.Get()) {
+		return true
+	}
+
+	for {
+		select {
+		case event := <-events:
+			if until(&event.State) {
+				return true
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func stateEvents(old, state State, now time.Time) (events []StateEvent) {
+	add := func(eventType StateEventType) {
+		events = append(events, StateEvent{Type: eventType, State: state, Time: now})
+	}
+
+	if !old.Live && state.Live {
+		add(EventPoweredOn)
+	}
+	if !old.OsReady && state.OsReady {
+		add(EventSSHUp)
+	}
+	if !old.AllSystemsReady && state.AllSystemsReady {
+		add(EventSystemsReady)
+	}
+	if old.Live && !state.Live {
+		add(EventWentDown)
+	}
+
+	return
+}
@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func eventTypes(events []StateEvent) (types []StateEventType) {
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return
+}
+
+func TestStateStorePublishesTheTransitions(t *testing.T) {
+	assert := assert.New(t)
+	states := NewStateStore()
+	events, unsubscribe := states.Subscribe()
+	defer unsubscribe()
+
+	assert.Equal([]StateEventType{EventPoweredOn}, eventTypes(states.Set(State{Live: true})))
+	assert.Empty(states.Set(State{Live: true}))
+	assert.Equal(
+		[]StateEventType{EventSSHUp, EventSystemsReady},
+		eventTypes(states.Set(State{Live: true, OsReady: true, AllSystemsReady: true})),
+	)
+	assert.Equal([]StateEventType{EventWentDown}, eventTypes(states.Set(State{Saved: true})))
+
+	var received []StateEventType
+	for len(events) > 0 {
+		event := <-events
+		received = append(received, event.Type)
+	}
+	assert.Equal([]StateEventType{EventPoweredOn, EventSSHUp, EventSystemsReady, EventWentDown}, received)
+	assert.Equal(&State{Saved: true}, states.Get())
+}
+
+func TestStateStoreStopsPublishingOnceUnsubscribed(t *testing.T) {
+	assert := assert.New(t)
+	states := NewStateStore()
+	events, unsubscribe := states.Subscribe()
+
+	unsubscribe()
+	states.Set(State{Live: true})
+	assert.Len(events, 0)
+}
+
+func TestStateStoreWaitsForTheState(t *testing.T) {
+	assert := assert.New(t)
+	states := NewStateStore()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		states.Set(State{Live: true})
+		states.Set(State{Live: true, OsReady: true, AllSystemsReady: true})
+	}()
+
+	ready := states.Wait(context.Background(), func(state *State) bool { return state.AllSystemsReady })
+	assert.True(ready)
+}
+
+func TestStateStoreWaitReturnsWhenCanceled(t *testing.T) {
+	assert := assert.New(t)
+	states := NewStateStore()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	ready := states.Wait(ctx, func(state *State) bool { return state.Live })
+	assert.False(ready)
+}
+
+func TestStateStoreSignalsTheSubscriptions(t *testing.T) {
+	assert := assert.New(t)
+	states := NewStateStore()
+	assert.False(states.HasSubscribers())
+
+	_, unsubscribe := states.Subscribe()
+	select {
+	case <-states.Subscribed():
+	default:
+		assert.Fail("the subscription has not been signaled")
+	}
+	assert.True(states.HasSubscribers())
+
+	unsubscribe()
+	assert.False(states.HasSubscribers())
+}
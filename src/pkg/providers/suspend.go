@@ -0,0 +1,10 @@
+package providers
+
+// Suspender is implemented by the providers able to save the state of a running VM,
+// the saved VM is resumed by Start
+type Suspender interface {
+	Suspend() error
+	IsSaved() (bool, error)
+	// Resumed tells whether the last Start restored a saved state
+	Resumed() bool
+}
@@ -16,6 +16,9 @@ func (t *Testing) Start() (err error) { return }
 //Stop VM
 func (t *Testing) Stop() (err error) { return }
 
+//PowerOff VM
+func (t *Testing) PowerOff() (err error) { return }
+
 //Unregister VM
 func (t *Testing) Unregister() (err error) { return }
 
@@ -25,9 +28,15 @@ func (t *Testing) Update() (updated bool, err error) { return }
 // GetState VM
 func (t *Testing) GetState() (state *State) { return }
 
+// StateStore VM
+func (t *Testing) StateStore() (states *StateStore) { return }
+
 // CheckIsUpdated VM
 func (t *Testing) CheckIsUpdated() (updated bool, err error) { return }
 
+// AddAction VM
+func (t *Testing) AddAction(LifecyclePoint, func() error) { return }
+
 //AddPostStartAction VM
 func (t *Testing) AddPostStartAction(func() error) { return }
 
@@ -2,51 +2,76 @@ package providers
 
 import (
 	"bufio"
-	"denver/pkg/util"
+	"denver/pkg/providers/virtualbox"
 	"denver/pkg/util/executor"
 	"denver/structs"
 	"fmt"
+	"log"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // TypeVirtualbox used to discriminate between other implementations
 var TypeVirtualbox = "virtualbox"
 
+var (
+	// Queries change nothing, they are bounded in case VBoxSVC hangs
+	queryOptions = executor.Options{Timeout: 30 * time.Second, Interruptible: true}
+	// Disk and state operations report their progress
+	progressOptions = executor.Options{Stream: true}
+	// Undoing a failed init goes on after a Ctrl-C, the VM would be left half created otherwise
+	cleanupOptions = executor.Options{Cleanup: true}
+)
+
 // Virtualbox implementation
 type Virtualbox struct {
-	provider     structs.Provider
-	instance     *structs.InstanceConf
-	boxPath      string
-	userData     string
-	userDataSize int
-	executor     *executor.Executor
-	state        *State
-	updater      VMUpdater
-
-	postStartActions []func() error
-	preStopActions   []func() error
+	provider       structs.Provider
+	instance       *structs.InstanceConf
+	boxPath        string
+	storePath      string
+	userData       string
+	userDataSize   int
+	executor       executor.Executor
+	states         *StateStore
+	updater        VMUpdater
+	snapshots      *snapshotIndex
+	resumed        bool
+	initJournal    *initJournal
+	instanceStates *instanceStateFile
+	dryRun         bool
+	// createdHostOnlyIfs is shared by all the instances, only these interfaces are pruned
+	createdHostOnlyIfs *hostOnlyIfIndex
+
+	*lifecycle
 }
 
 func newVirtualBox(
 	provider structs.Provider,
 	instance *structs.InstanceConf,
 	boxPath string,
-	executor *executor.Executor,
-	userDataSize int,
+	executor executor.Executor,
 	updater VMUpdater,
 	workingDirectory string,
 ) *Virtualbox {
+	storePath := filepath.Join(workingDirectory, instance.Store)
 	return &Virtualbox{
-		provider:     provider,
-		instance:     instance,
-		boxPath:      boxPath,
-		userData:     filepath.Join(workingDirectory, "store", "userdata.vdi"),
-		userDataSize: userDataSize,
-		executor:     executor,
-		state:        NewState(),
-		updater:      updater,
+		provider:       provider,
+		instance:       instance,
+		boxPath:        boxPath,
+		storePath:      storePath,
+		userData:       userDataPath(workingDirectory, instance, "userdata.vdi"),
+		userDataSize:   instance.Userdatasize,
+		executor:       executor,
+		states:         NewStateStore(),
+		updater:        updater,
+		lifecycle:      newLifecycle(),
+		snapshots:      newSnapshotIndex(storePath),
+		initJournal:    newInitJournal(storePath),
+		instanceStates: newInstanceStateFile(storePath),
+
+		createdHostOnlyIfs: newHostOnlyIfIndex(workingDirectory),
 	}
 }
 
@@ -63,6 +88,11 @@ func (v *Virtualbox) Init() (err error) {
 	}
 
 	if exists {
+		if interrupted, err := v.initJournal.exists(); err != nil {
+			return err
+		} else if interrupted {
+			return fmt.Errorf("%s has been partially initialized, run init --resume", v.instance.Name)
+		}
 		return fmt.Errorf("%s already exists", v.instance.Name)
 	}
 	return v.init()
@@ -82,6 +112,26 @@ func (v *Virtualbox) Start() (err error) {
 		return fmt.Errorf("%s is running", v.instance.Name)
 	}
 
+	if err = checkAvailableMemory(v.instance.Vmem); err != nil {
+		return
+	}
+
+	// A saved VM can't be modified, it is resumed as it was
+	saved, err := v.IsSaved()
+	if err != nil {
+		return
+	}
+
+	if err = v.run(PreStart); err != nil {
+		return
+	}
+
+	if !saved {
+		if err = v.reconcile(); err != nil {
+			return
+		}
+	}
+
 	cmd := []string{
 		"VBoxManage",
 		"startvm",
@@ -89,7 +139,13 @@ func (v *Virtualbox) Start() (err error) {
 		"--type",
 		"gui",
 	}
-	_, err = v.executor.Execute(cmd)
+	v.expect(PostStart)
+	if _, err = v.executor.Execute(cmd); err != nil {
+		v.unexpect(PostStart)
+		return
+	}
+
+	v.resumed = saved
 	return
 }
 
@@ -107,7 +163,7 @@ func (v *Virtualbox) Stop() error {
 		return fmt.Errorf("%s is not running", v.instance.Name)
 	}
 
-	if err := v.executePreStopActions(); err != nil {
+	if err := v.run(PreStop); err != nil {
 		return err
 	}
 
@@ -117,13 +173,58 @@ func (v *Virtualbox) Stop() error {
 		v.instance.Name,
 		"acpipowerbutton",
 	}
-	_, err := v.executor.Execute(cmd)
-	return err
+	return v.stopWith(cmd)
+}
+
+// PowerOff cuts the power of the VM, the guest is not shut down and the pre-stop actions are not run
+func (v *Virtualbox) PowerOff() error {
+	if exists, err := v.checkIfExists(); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("%s does not exists", v.instance.Name)
+	}
+
+	if isRunning, err := v.checkIfRunning(); err != nil {
+		return err
+	} else if !isRunning {
+		return fmt.Errorf("%s is not running", v.instance.Name)
+	}
+
+	cmd := []string{
+		"VBoxManage",
+		"controlvm",
+		v.instance.Name,
+		"poweroff",
+	}
+	return v.stopWith(cmd)
+}
+
+// stopWith runs the command stopping the VM, the post-stop actions run once it is down
+func (v *Virtualbox) stopWith(cmd []string) error {
+	v.expect(PostStop)
+	if _, err := v.executor.Execute(cmd); err != nil {
+		v.unexpect(PostStop)
+		return err
+	}
+
+	return nil
 }
 
 // GetState VM
 func (v *Virtualbox) GetState() *State {
-	return v.state
+	return v.states.Get()
+}
+
+// StateStore VM
+func (v *Virtualbox) StateStore() *StateStore {
+	return v.states
+}
+
+// background returns the view of the VM used by the probe, its commands are left out of the recordings
+func (v *Virtualbox) background() VMProvider {
+	view := *v
+	view.executor = executor.NewBackgroundExecutor(v.executor)
+	return &view
 }
 
 // CheckIsUpdated VM
@@ -138,6 +239,10 @@ func (v *Virtualbox) Update() (updated bool, err error) {
 		return
 	}
 
+	if err = v.run(PreUpdate); err != nil {
+		return
+	}
+
 	err = v.unregisterIfExists()
 	if err != nil {
 		return
@@ -148,7 +253,11 @@ func (v *Virtualbox) Update() (updated bool, err error) {
 		return
 	}
 
-	return true, v.init()
+	if err = v.init(); err != nil {
+		return
+	}
+
+	return true, v.run(PostUpdate)
 }
 
 func (v *Virtualbox) unregisterIfExists() (err error) {
@@ -164,161 +273,72 @@ func (v *Virtualbox) unregisterIfExists() (err error) {
 
 // Unregister a VM
 func (v *Virtualbox) Unregister() (err error) {
-	hostIf, err := v.infoFromVM("hostonlyadapter2=\"(.*)\"")
-	if err != nil {
-		return
-	}
-
-	rootVol, err := v.infoFromVM("SAS-0-0\"=\"(.*)\"")
-	if err != nil {
-		return
-	}
-
-	userVol, err := v.infoFromVM("SAS-1-0\"=\"(.*)\"")
-	if err != nil {
-		return
-	}
-
-	cmd0 := []string{
-		"VBoxManage", "hostonlyif", "remove", hostIf,
-	}
-	_, err = v.executor.Execute(cmd0)
-	if err != nil {
-		return
-	}
-
-	cmd1 := []string{
-		"VBoxManage", "storageattach", v.instance.Name,
-		"--storagectl", "SAS",
-		"--port", "0",
-		"--medium", "none",
-	}
-	_, err = v.executor.Execute(cmd1)
-	if err != nil {
-		return
-	}
-
-	cmd2 := []string{
-		"VBoxManage", "closemedium", "disk", rootVol,
-	}
-	_, err = v.executor.Execute(cmd2)
-	if err != nil {
-		return
-	}
-
-	cmd3 := []string{
-		"VBoxManage", "storageattach", v.instance.Name,
-		"--storagectl", "SAS",
-		"--port", "1",
-		"--medium", "none",
-	}
-	_, err = v.executor.Execute(cmd3)
+	state, err := v.InstanceState()
 	if err != nil {
 		return
 	}
 
-	cmd4 := []string{
-		"VBoxManage", "closemedium", "disk", userVol,
-	}
-	_, err = v.executor.Execute(cmd4)
-	if err != nil {
+	// The disks hold the current state only once the snapshots are merged back into them,
+	// unregistervm --delete would remove the differencing disks of the snapshots otherwise
+	if err = v.deleteSnapshots(); err != nil {
 		return
 	}
 
-	cmd5 := []string{
-		"VBoxManage", "unregistervm", v.instance.Name, "--delete",
+	vm := state.VMUUID
+	if vm == "" {
+		vm = v.instance.Name
 	}
-	_, err = v.executor.Execute(cmd5)
-
-	return
-}
-
-func (v *Virtualbox) setState(state *State) (err error) {
-	oldState := v.state
-	v.state = state
-
-	if !oldState.AllSystemsReady && v.state.AllSystemsReady {
-		return v.executePostStartActions()
-	}
-
-	return
-}
-
-// AddPostStartAction triggers an action just after starting the VM
-func (v *Virtualbox) AddPostStartAction(f func() error) {
-	v.postStartActions = append(v.postStartActions, f)
-}
-
-// AddPreStopAction triggers an action just before stopping the VM
-func (v *Virtualbox) AddPreStopAction(f func() error) {
-	v.postStartActions = append(v.postStartActions, f)
-}
 
-func (v *Virtualbox) executePreStopActions() error {
-	for _, x := range v.preStopActions {
-		if err := x(); err != nil {
+	// The interface may be gone already, or still be used by another instance of the same subnet,
+	// `network prune` cleans what is left behind anyway
+	if state.HostOnlyIf != "" && state.HostOnlyIfCreated {
+		used, err := v.usedHostOnlyIfs(vm)
+		if err != nil {
 			return err
 		}
-	}
 
-	return nil
-}
-
-func (v *Virtualbox) executePostStartActions() error {
-	for _, x := range v.postStartActions {
-		if err := x(); err != nil {
+		if used[state.HostOnlyIf] {
+			log.Printf("Keeping the host-only interface %s, another VM uses it", state.HostOnlyIf)
+		} else if err = v.removeHostOnlyNetwork(state.HostOnlyIf, executor.Options{}); err != nil {
 			return err
 		}
 	}
 
-	return nil
-}
-
-func (v *Virtualbox) init() (err error) {
-	if err := v.install(); err != nil {
-		return err
-	}
-
-	if err := v.setDefaultOptions(); err != nil {
-		return err
-	}
-
-	if err := v.setCPU(fmt.Sprintf("%d", v.instance.Vcpu)); err != nil {
-		return err
-	}
+	// Disks are detached first, unregistervm --delete would remove them otherwise
+	for port, medium := range []string{state.RootMedium, state.UserDataMedium} {
+		if medium == "" {
+			continue
+		}
 
-	if err := v.setMEM(fmt.Sprintf("%d", v.instance.Vmem)); err != nil {
-		return err
-	}
+		if err = v.detachDisk(strconv.Itoa(port), executor.Options{}); err != nil {
+			return
+		}
 
-	hostonlyif, err := v.createHostOnlyNetwork(v.instance.Localip)
-	if err != nil {
-		return err
+		cmd := []string{
+			"VBoxManage", "closemedium", "disk", medium,
+		}
+		_, err = v.executor.Execute(cmd)
+		if err != nil {
+			return
+		}
 	}
 
-	if err := v.attachHostOnlyNetwork(hostonlyif); err != nil {
-		return err
+	cmd5 := []string{
+		"VBoxManage", "unregistervm", vm, "--delete",
 	}
-
-	if err := v.setStorageCtl(); err != nil {
-		return err
+	if _, err = v.executor.ExecuteWithOptions(cmd5, progressOptions); err != nil || v.dryRun {
+		return
 	}
 
-	if err := v.attachRootImage(); err != nil {
-		return err
+	if err = v.snapshots.clear(); err != nil {
+		return
 	}
 
-	fileExist, _ := util.Exists(v.userData)
-	if !fileExist {
-		if err := v.createUserData(fmt.Sprintf("%d", (v.userDataSize * 1024))); err != nil {
-			return err
-		}
-	}
-	if err := v.attachUserData(); err != nil {
-		return err
-	}
+	return v.instanceStates.remove()
+}
 
-	return nil
+func (v *Virtualbox) setState(state *State) (err error) {
+	return v.publish(v.states, *state)
 }
 
 func (v *Virtualbox) install() (err error) {
@@ -341,7 +361,7 @@ func (v *Virtualbox) checkIfRunning() (bool, error) {
 		"runningvms",
 	}
 
-	vms, err := v.executor.Execute(cmd)
+	vms, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
 	if err != nil {
 		return false, err
 	}
@@ -356,7 +376,7 @@ func (v *Virtualbox) checkIfExists() (bool, error) {
 		"vms",
 	}
 
-	vms, err := v.executor.Execute(cmd)
+	vms, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
 	if err != nil {
 		return false, err
 	}
@@ -413,37 +433,6 @@ func (v *Virtualbox) setDefaultOptions() error {
 	return err
 }
 
-func (v *Virtualbox) createHostOnlyNetwork(localip string) (string, error) {
-	var hostonlyif string
-	cmdCreate := []string{
-		"VBoxManage",
-		"hostonlyif",
-		"create",
-	}
-	output, _ := v.executor.Execute(cmdCreate)
-	re := regexp.MustCompile(`Interface '([^']+)' was successfully created`)
-	matches := re.FindStringSubmatch(output)
-	if len(matches) >= 2 {
-		hostonlyif = matches[1]
-	} else {
-		return "", fmt.Errorf("could not determine the interface name from vbox output: %s", output)
-	}
-
-	localiparray := strings.Split(localip, ".")
-	localip = localiparray[0] + "." + localiparray[1] + "." + localiparray[2] + ".1"
-	cmdConf := []string{
-		"VBoxManage", "hostonlyif",
-		"ipconfig", hostonlyif,
-		"--ip", localip,
-		"--netmask", "255.255.255.0",
-	}
-	if _, err := v.executor.Execute(cmdConf); err != nil {
-		return "", err
-	}
-
-	return hostonlyif, nil
-}
-
 func (v *Virtualbox) attachHostOnlyNetwork(hostonlyif string) error {
 	cmd := []string{
 		"VBoxManage", "modifyvm", v.instance.Name,
@@ -520,7 +509,7 @@ func (v *Virtualbox) createUserData(size string) error {
 		"--format", "VDI",
 		"--variant", "Standard",
 	}
-	_, err := v.executor.Execute(cmd)
+	_, err := v.executor.ExecuteWithOptions(cmd, progressOptions)
 	return err
 }
 
@@ -539,18 +528,45 @@ func (v *Virtualbox) attachUserData() error {
 	return err
 }
 
-func (v *Virtualbox) infoFromVM(search string) (string, error) {
+func (v *Virtualbox) machineReadableInfo() (map[string]string, error) {
 	cmd := []string{
 		"VBoxManage", "showvminfo",
 		"--machinereadable", v.instance.Name,
 	}
-	stdOut, err := v.executor.Execute(cmd)
+	stdOut, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
+	if err != nil {
+		return nil, err
+	}
 
-	re := regexp.MustCompile(fmt.Sprintf(`%s`, search))
-	matches := re.FindStringSubmatch(stdOut)
-	if len(matches) > 0 {
-		return matches[1], err
+	return parseMachineReadable(stdOut), nil
+}
+
+// parseMachineReadable turns `key="value"` lines into a map, quotes being removed
+func parseMachineReadable(out string) map[string]string {
+	info := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		separator := strings.Index(line, "=")
+		if separator <= 0 {
+			continue
+		}
+
+		key := strings.Trim(line[:separator], "\"")
+		value := strings.Trim(line[separator+1:], "\"")
+		info[key] = value
 	}
 
-	return "", fmt.Errorf("unable to retrieve information from the Virtual Machine")
+	return info
+}
+
+// UserDataPath returns the path of the userdata disk
+func (v *Virtualbox) UserDataPath() string {
+	return v.userData
+}
+
+// GetRBIManifest returns the manifest of the installed Root Base Image
+func (v *Virtualbox) GetRBIManifest() (virtualbox.Manifest, error) {
+	return v.updater.GetLocalManifest()
 }
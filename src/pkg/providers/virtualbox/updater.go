@@ -3,10 +3,12 @@ package virtualbox
 import (
 	"context"
 	"denver/pkg/backup"
+	"denver/pkg/host"
 	"denver/pkg/storage"
 	"denver/pkg/util"
 	"denver/pkg/util/compressor"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -118,17 +120,21 @@ func (b *Updater) Update() (err error) {
 		return
 	}
 
-	log.Println("Downloading box...")
-	fileName, err := b.storage.Download(b.boxURL, dir)
+	manifest, err := b.getManifest(manifestFile)
 	if err != nil {
 		return
 	}
+	if err = b.checkFreeDiskSpace(manifest, dir); err != nil {
+		return
+	}
 
-	log.Println("Decompressing...")
-	manifest, err := b.getManifest(manifestFile)
+	log.Println("Downloading box...")
+	fileName, err := b.storage.Download(b.boxURL, dir)
 	if err != nil {
 		return
 	}
+
+	log.Println("Decompressing...")
 	err = b.compressor.Decompress(fileName, dir, manifest.FileSize)
 	if err != nil {
 		return
@@ -172,6 +178,45 @@ func (b *Updater) Update() (err error) {
 	return b.backup.Remove()
 }
 
+// checkFreeDiskSpace refuses to download a box which would not fit. The download and its decompression
+// take CompressedSize + FileSize in dir, the box then takes ImageSize next to the current one
+func (b *Updater) checkFreeDiskSpace(manifest Manifest, dir string) error {
+	needs := []struct {
+		path string
+		size int
+	}{
+		{dir, manifest.CompressedSize + manifest.FileSize},
+		{b.workingDirectory, manifest.ImageSize},
+	}
+
+	for _, need := range needs {
+		free, known, err := host.FreeDiskSpace(need.path)
+		if err != nil {
+			return err
+		}
+		if !known {
+			continue
+		}
+
+		if needed := uint64(need.size); free < needed {
+			return fmt.Errorf(
+				"%d MB are free in %s but the RBI %s needs %d MB there, free some disk space first",
+				free/1024/1024,
+				need.path,
+				manifest.Version,
+				needed/1024/1024,
+			)
+		}
+	}
+
+	return nil
+}
+
+// GetLocalManifest returns the manifest of the box currently installed
+func (b *Updater) GetLocalManifest() (manifest Manifest, err error) {
+	return b.getManifest(b.manifestPath)
+}
+
 func (b *Updater) getManifestFilePath() (path string, err error) {
 	dir, err := ioutil.TempDir("", "manifest")
 	if err != nil {
@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"denver/pkg/util"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+var capacityRegexp = regexp.MustCompile(`Capacity:\s+([0-9]+) MBytes`)
+
+// UserDataSize returns the capacity of the userdata disk
+func (v *Virtualbox) UserDataSize() (int, error) {
+	cmd := []string{
+		"VBoxManage", "showmediuminfo", "disk", v.userData,
+	}
+	out, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
+	if err != nil {
+		return 0, err
+	}
+
+	matches := capacityRegexp.FindStringSubmatch(out)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("unable to retrieve the capacity of %s", v.userData)
+	}
+
+	return strconv.Atoi(matches[1])
+}
+
+// GrowUserData resizes the userdata disk, the guest filesystem is grown on next boot
+func (v *Virtualbox) GrowUserData(size int) (err error) {
+	if isRunning, err := v.checkIfRunning(); err != nil {
+		return err
+	} else if isRunning {
+		return fmt.Errorf("%s is running", v.instance.Name)
+	}
+
+	current, err := v.UserDataSize()
+	if err != nil {
+		return
+	}
+	if size <= current {
+		return fmt.Errorf("refusing to shrink the userdata disk from %d MB to %d MB", current, size)
+	}
+
+	cmd := []string{
+		"VBoxManage", "modifymedium", "disk", v.userData,
+		"--resize", strconv.Itoa(size),
+	}
+	if _, err = v.executor.ExecuteWithOptions(cmd, progressOptions); err != nil || v.dryRun {
+		return
+	}
+
+	if err = os.MkdirAll(v.storePath, os.ModePerm); err != nil {
+		return
+	}
+
+	return ioutil.WriteFile(v.guestGrowMarker(), []byte(strconv.Itoa(size)), 0644)
+}
+
+// GuestGrowPending tells whether the guest filesystem still has to be grown
+func (v *Virtualbox) GuestGrowPending() (bool, error) {
+	return util.Exists(v.guestGrowMarker())
+}
+
+// SetGuestGrowDone acknowledges the guest filesystem has been grown
+func (v *Virtualbox) SetGuestGrowDone() error {
+	if err := os.Remove(v.guestGrowMarker()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (v *Virtualbox) guestGrowMarker() string {
+	return filepath.Join(v.storePath, "userdata.grow")
+}
@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VBoxManage writes the state change time in UTC, without zone
+const vboxTimeLayout = "2006-01-02T15:04:05.999999999"
+
+var (
+	nicRegexp     = regexp.MustCompile(`^nic([0-9]+)$`)
+	storageRegexp = regexp.MustCompile(`^(.+)-([0-9]+)-([0-9]+)$`)
+)
+
+// Info returns the description of the VM
+func (v *Virtualbox) Info() (*VMInfo, error) {
+	if exists, err := v.checkIfExists(); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("%s does not exists", v.instance.Name)
+	}
+
+	return v.vmInfo()
+}
+
+func (v *Virtualbox) vmInfo() (*VMInfo, error) {
+	cmd := []string{
+		"VBoxManage", "showvminfo",
+		"--machinereadable", v.instance.Name,
+	}
+	out, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseVMInfo(out, time.Now()), nil
+}
+
+// parseVMInfo turns the output of `showvminfo --machinereadable` into a VMInfo
+func parseVMInfo(out string, now time.Time) *VMInfo {
+	info := parseMachineReadable(out)
+
+	vmInfo := &VMInfo{
+		Name:      info["name"],
+		UUID:      info["UUID"],
+		State:     info["VMState"],
+		NICs:      []NIC{},
+		Storage:   []StorageAttachment{},
+		Snapshots: []string{},
+	}
+	vmInfo.CPUs, _ = strconv.Atoi(info["cpus"])
+	vmInfo.Memory, _ = strconv.Atoi(info["memory"])
+
+	if since, err := time.Parse(vboxTimeLayout, info["VMStateChangeTime"]); err == nil {
+		vmInfo.StateSince = since
+		if vmInfo.State == "running" {
+			vmInfo.Uptime = now.Sub(since).Round(time.Second).String()
+		}
+	}
+
+	controllers := map[string]bool{}
+	for key, value := range info {
+		if strings.HasPrefix(key, "storagecontrollername") {
+			controllers[value] = true
+		}
+	}
+
+	for key, value := range info {
+		if matches := nicRegexp.FindStringSubmatch(key); matches != nil {
+			if value == "none" {
+				continue
+			}
+			index, _ := strconv.Atoi(matches[1])
+			vmInfo.NICs = append(vmInfo.NICs, NIC{
+				Index:   index,
+				Type:    value,
+				Network: nicNetwork(info, value, matches[1]),
+				MAC:     info["macaddress"+matches[1]],
+			})
+			continue
+		}
+
+		if matches := storageRegexp.FindStringSubmatch(key); matches != nil && controllers[matches[1]] {
+			if value == "none" || value == "emptydrive" {
+				continue
+			}
+			port, _ := strconv.Atoi(matches[2])
+			device, _ := strconv.Atoi(matches[3])
+			vmInfo.Storage = append(vmInfo.Storage, StorageAttachment{
+				Controller: matches[1],
+				Port:       port,
+				Device:     device,
+				Medium:     value,
+				UUID:       info[fmt.Sprintf("%s-ImageUUID-%s-%s", matches[1], matches[2], matches[3])],
+			})
+		}
+	}
+
+	sort.Slice(vmInfo.NICs, func(i, j int) bool {
+		return vmInfo.NICs[i].Index < vmInfo.NICs[j].Index
+	})
+	sort.Slice(vmInfo.Storage, func(i, j int) bool {
+		a, b := vmInfo.Storage[i], vmInfo.Storage[j]
+		if a.Controller != b.Controller {
+			return a.Controller < b.Controller
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.Device < b.Device
+	})
+
+	// Snapshots are listed in tree order, the map of the output can't keep it
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		matches := snapshotNameRegexp.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if len(matches) == 3 {
+			vmInfo.Snapshots = append(vmInfo.Snapshots, matches[2])
+		}
+	}
+
+	return vmInfo
+}
+
+func nicNetwork(info map[string]string, nicType, index string) string {
+	switch nicType {
+	case "hostonly":
+		return info["hostonlyadapter"+index]
+	case "bridged":
+		return info["bridgeadapter"+index]
+	case "intnet":
+		return info["intnet"+index]
+	}
+
+	return ""
+}
@@ -0,0 +1,179 @@
+package providers
+
+import (
+	"denver/pkg/util"
+	"denver/pkg/util/executor"
+	"fmt"
+)
+
+// ResumeInit continues an init which has been interrupted or could not be cleaned up
+func (v *Virtualbox) ResumeInit() (err error) {
+	progress, err := v.initJournal.read()
+	if err != nil {
+		return fmt.Errorf("there is no init of %s to resume", v.instance.Name)
+	}
+
+	if v.dryRun {
+		return v.previewInitSteps(progress)
+	}
+
+	if err = runInitSteps(v.initJournal, progress, v.initSteps(progress)); err != nil {
+		return
+	}
+
+	return v.recordInstanceState(progress)
+}
+
+func (v *Virtualbox) init() (err error) {
+	progress := &initProgress{}
+	if v.dryRun {
+		return v.previewInitSteps(progress)
+	}
+
+	if err = runInitSteps(v.initJournal, progress, v.initSteps(progress)); err != nil {
+		return
+	}
+
+	return v.recordInstanceState(progress)
+}
+
+// previewInitSteps runs the steps which are not done yet in dry-run. Nothing is created, there is
+// nothing to journal, to undo nor to record
+func (v *Virtualbox) previewInitSteps(progress *initProgress) (err error) {
+	for _, step := range v.initSteps(progress) {
+		if progress.isDone(step.name) {
+			continue
+		}
+		if err = step.do(); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (v *Virtualbox) initSteps(progress *initProgress) []initStep {
+	return []initStep{
+		{
+			name: "createvm",
+			do:   v.install,
+			undo: func() error {
+				_, err := v.executor.ExecuteWithOptions([]string{
+					"VBoxManage", "unregistervm", v.instance.Name, "--delete",
+				}, cleanupOptions)
+				return err
+			},
+		},
+		{
+			name: "options",
+			do:   v.setDefaultOptions,
+		},
+		{
+			name: "ports",
+			do: func() error {
+				// A dry-run creates nothing, there is no VM to read the forwards from
+				if v.dryRun {
+					return v.applyPortForwards(nil)
+				}
+				return v.syncPortForwards()
+			},
+		},
+		{
+			name: "cpu",
+			do: func() error {
+				return v.setCPU(fmt.Sprintf("%d", v.instance.Vcpu))
+			},
+		},
+		{
+			name: "memory",
+			do: func() error {
+				return v.setMEM(fmt.Sprintf("%d", v.instance.Vmem))
+			},
+		},
+		{
+			name: "hostonlyif",
+			do: func() (err error) {
+				progress.HostOnlyIf, progress.HostOnlyIfCreated, err = v.createHostOnlyNetwork(v.instance.Localip)
+				return
+			},
+			undo: func() error {
+				// A reused interface was there before the init, it is kept
+				if !progress.HostOnlyIfCreated {
+					return nil
+				}
+				return v.removeHostOnlyNetwork(progress.HostOnlyIf, cleanupOptions)
+			},
+		},
+		{
+			name: "attach-hostonlyif",
+			do: func() error {
+				return v.attachHostOnlyNetwork(progress.HostOnlyIf)
+			},
+		},
+		{
+			name: "storagectl",
+			do:   v.setStorageCtl,
+		},
+		{
+			// The root image is detached first, unregistervm --delete would remove it otherwise
+			name: "attach-root",
+			do:   v.attachRootImage,
+			undo: func() (err error) {
+				if err = v.detachDisk("0", cleanupOptions); err != nil {
+					return
+				}
+				_, err = v.executor.ExecuteWithOptions([]string{
+					"VBoxManage", "closemedium", "disk", v.boxPath,
+				}, cleanupOptions)
+				return
+			},
+		},
+		{
+			name: "create-userdata",
+			do: func() (err error) {
+				if exists, _ := util.Exists(v.userData); exists {
+					return
+				}
+				if err = v.createUserData(fmt.Sprintf("%d", (v.userDataSize * 1024))); err != nil {
+					return
+				}
+				progress.UserDataCreated = true
+				return
+			},
+			undo: func() (err error) {
+				if !progress.UserDataCreated {
+					return
+				}
+				// The disk has just been created, it holds nothing yet
+				_, err = v.executor.ExecuteWithOptions([]string{
+					"VBoxManage", "closemedium", "disk", v.userData, "--delete",
+				}, cleanupOptions)
+				return
+			},
+		},
+		{
+			name: "attach-userdata",
+			do:   v.attachUserData,
+			undo: func() (err error) {
+				if err = v.detachDisk("1", cleanupOptions); err != nil || progress.UserDataCreated {
+					return
+				}
+				_, err = v.executor.ExecuteWithOptions([]string{
+					"VBoxManage", "closemedium", "disk", v.userData,
+				}, cleanupOptions)
+				return
+			},
+		},
+	}
+}
+
+func (v *Virtualbox) detachDisk(port string, options executor.Options) error {
+	cmd := []string{
+		"VBoxManage", "storageattach", v.instance.Name,
+		"--storagectl", "SAS",
+		"--port", port,
+		"--medium", "none",
+	}
+	_, err := v.executor.ExecuteWithOptions(cmd, options)
+	return err
+}
@@ -0,0 +1,387 @@
+package providers
+
+import (
+	"bufio"
+	"denver/pkg/host"
+	"denver/pkg/util/executor"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hostOnlyMask is the mask of the host-only networks created by denver, the host being .1
+const hostOnlyMask = "255.255.255.0"
+
+// hostNetworks returns the networks of the host interfaces and the destinations of the routes of
+// the host, a VPN or a container network may only show up in the latter. It is replaced in tests.
+var hostNetworks = func() (networks []*net.IPNet, routes []*net.IPNet, err error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return
+	}
+
+	for _, addr := range addrs {
+		if network, ok := addr.(*net.IPNet); ok {
+			networks = append(networks, network)
+		}
+	}
+
+	// The routes can't be read everywhere, the interfaces are checked anyway
+	if routes, err = host.Routes(); err != nil {
+		log.Printf("Unable to read the routes of the host, only its interfaces are checked: %s", err)
+		return networks, nil, nil
+	}
+
+	return
+}
+
+// hostOnlyIf describes a VirtualBox host-only interface
+type hostOnlyIf struct {
+	Name string
+	IP   string
+	Mask string
+}
+
+func (h hostOnlyIf) network() *net.IPNet {
+	ip := net.ParseIP(h.IP).To4()
+	mask := net.ParseIP(h.Mask).To4()
+	if ip == nil || mask == nil {
+		return nil
+	}
+
+	return &net.IPNet{IP: ip.Mask(net.IPMask(mask)), Mask: net.IPMask(mask)}
+}
+
+// createHostOnlyNetwork returns the host-only interface of the localip subnet, it is only
+// created when no interface is configured for this subnet yet
+func (v *Virtualbox) createHostOnlyNetwork(localip string) (hostonlyif string, created bool, err error) {
+	gateway, err := hostOnlyGateway(localip)
+	if err != nil {
+		return
+	}
+
+	ifs, err := v.hostOnlyIfs()
+	if err != nil {
+		return
+	}
+
+	for _, i := range ifs {
+		if i.IP == gateway && i.Mask == hostOnlyMask {
+			log.Printf("Reusing the host-only interface %s configured for %s", i.Name, gateway)
+			return i.Name, false, nil
+		}
+	}
+
+	networks, routes, err := hostNetworks()
+	if err != nil {
+		return
+	}
+	if err = checkSubnetConflicts(gateway, ifs, networks, routes); err != nil {
+		return
+	}
+
+	cmdCreate := []string{
+		"VBoxManage",
+		"hostonlyif",
+		"create",
+	}
+	output, _ := v.executor.Execute(cmdCreate)
+	re := regexp.MustCompile(`Interface '([^']+)' was successfully created`)
+	matches := re.FindStringSubmatch(output)
+	if len(matches) >= 2 {
+		hostonlyif = matches[1]
+	} else if v.dryRun {
+		// VirtualBox names the interface, it is only known once created
+		hostonlyif = "<new host-only interface>"
+	} else {
+		return "", false, fmt.Errorf("could not determine the interface name from vbox output: %s", output)
+	}
+
+	cmdConf := []string{
+		"VBoxManage", "hostonlyif",
+		"ipconfig", hostonlyif,
+		"--ip", gateway,
+		"--netmask", hostOnlyMask,
+	}
+	if _, err = v.executor.Execute(cmdConf); err != nil {
+		_, _ = v.executor.Execute([]string{"VBoxManage", "hostonlyif", "remove", hostonlyif})
+		return "", false, err
+	}
+
+	if !v.dryRun {
+		if err = v.createdHostOnlyIfs.add(hostonlyif); err != nil {
+			_, _ = v.executor.Execute([]string{"VBoxManage", "hostonlyif", "remove", hostonlyif})
+			return "", false, err
+		}
+	}
+
+	return hostonlyif, true, nil
+}
+
+// removeHostOnlyNetwork removes a host-only interface created by denver
+func (v *Virtualbox) removeHostOnlyNetwork(hostonlyif string, options executor.Options) (err error) {
+	cmd := []string{
+		"VBoxManage", "hostonlyif", "remove", hostonlyif,
+	}
+	if _, err = v.executor.ExecuteWithOptions(cmd, options); err != nil || v.dryRun {
+		return
+	}
+
+	return v.createdHostOnlyIfs.remove(hostonlyif)
+}
+
+// PruneNetworks removes the host-only interfaces created by denver which are not used by any VM anymore,
+// the other ones are never touched
+func (v *Virtualbox) PruneNetworks() (removed []string, err error) {
+	created, err := v.createdHostOnlyIfs.read()
+	if err != nil {
+		return
+	}
+
+	ifs, err := v.hostOnlyIfs()
+	if err != nil {
+		return
+	}
+
+	used, err := v.usedHostOnlyIfs("")
+	if err != nil {
+		return
+	}
+
+	existing := map[string]bool{}
+	for _, i := range ifs {
+		existing[i.Name] = true
+		if !created[i.Name] || used[i.Name] {
+			continue
+		}
+
+		if err = v.removeHostOnlyNetwork(i.Name, executor.Options{}); err != nil {
+			return
+		}
+		removed = append(removed, i.Name)
+	}
+
+	// The ones removed outside of denver are forgotten
+	for name := range created {
+		if !existing[name] && !v.dryRun {
+			if err = v.createdHostOnlyIfs.remove(name); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func (v *Virtualbox) hostOnlyIfs() ([]hostOnlyIf, error) {
+	cmd := []string{
+		"VBoxManage", "list", "hostonlyifs",
+	}
+	out, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHostOnlyIfs(out), nil
+}
+
+// usedHostOnlyIfs returns the host-only interfaces attached to any registered VM, denver or not,
+// but the excluded one given by its name or UUID
+func (v *Virtualbox) usedHostOnlyIfs(exclude string) (used map[string]bool, err error) {
+	cmd := []string{
+		"VBoxManage", "list", "vms",
+	}
+	vms, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
+	if err != nil {
+		return
+	}
+
+	used = map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(vms))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// The UUID is used when there is one, VM names may contain spaces
+		vm := strings.Trim(fields[len(fields)-1], "{}")
+		if exclude != "" && (vm == exclude || strings.HasPrefix(scanner.Text(), fmt.Sprintf("%q ", exclude))) {
+			continue
+		}
+
+		cmd := []string{
+			"VBoxManage", "showvminfo",
+			"--machinereadable", vm,
+		}
+		out, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range parseMachineReadable(out) {
+			if strings.HasPrefix(key, "hostonlyadapter") {
+				used[value] = true
+			}
+		}
+	}
+
+	return
+}
+
+// parseHostOnlyIfs reads the blank line separated blocks of `VBoxManage list hostonlyifs`
+func parseHostOnlyIfs(out string) (ifs []hostOnlyIf) {
+	var current *hostOnlyIf
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		separator := strings.Index(line, ":")
+		if separator <= 0 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:separator])
+		value := strings.TrimSpace(line[separator+1:])
+		switch key {
+		case "Name":
+			ifs = append(ifs, hostOnlyIf{Name: value})
+			current = &ifs[len(ifs)-1]
+		case "IPAddress":
+			if current != nil {
+				current.IP = value
+			}
+		case "NetworkMask":
+			if current != nil {
+				current.Mask = value
+			}
+		}
+	}
+
+	return
+}
+
+// hostOnlyGateway returns the host side address of the localip /24 subnet
+func hostOnlyGateway(localip string) (string, error) {
+	ip := net.ParseIP(localip).To4()
+	if ip == nil {
+		return "", fmt.Errorf("localip %s is not a valid IPv4 address", localip)
+	}
+
+	return net.IPv4(ip[0], ip[1], ip[2], 1).String(), nil
+}
+
+// checkSubnetConflicts refuses a subnet overlapping another host-only interface, a host network or a route
+func checkSubnetConflicts(gateway string, ifs []hostOnlyIf, networks []*net.IPNet, routes []*net.IPNet) error {
+	wanted := hostOnlyIf{IP: gateway, Mask: hostOnlyMask}.network()
+
+	hostOnlyIPs := map[string]bool{}
+	for _, i := range ifs {
+		hostOnlyIPs[i.IP] = true
+
+		if network := i.network(); network != nil && overlaps(wanted, network) {
+			return fmt.Errorf("the %s subnet conflicts with the host-only interface %s (%s/%s)", wanted, i.Name, i.IP, i.Mask)
+		}
+	}
+
+	for _, network := range networks {
+		// Host-only interfaces are host interfaces too, they have been checked already
+		if network.IP.To4() == nil || hostOnlyIPs[network.IP.String()] {
+			continue
+		}
+
+		if overlaps(wanted, network) {
+			return fmt.Errorf("the %s subnet conflicts with the host network %s, change localip", wanted, network)
+		}
+	}
+
+	for _, route := range routes {
+		if overlaps(wanted, route) {
+			return fmt.Errorf("the %s subnet conflicts with the route to %s, change localip", wanted, route)
+		}
+	}
+
+	return nil
+}
+
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// hostOnlyIfIndex records the host-only interfaces created by denver, for all the instances
+type hostOnlyIfIndex struct {
+	path string
+}
+
+func newHostOnlyIfIndex(workingDirectory string) *hostOnlyIfIndex {
+	return &hostOnlyIfIndex{
+		path: filepath.Join(workingDirectory, "store", "hostonlyifs.json"),
+	}
+}
+
+func (h *hostOnlyIfIndex) read() (names map[string]bool, err error) {
+	names = map[string]bool{}
+
+	body, err := ioutil.ReadFile(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return names, nil
+		}
+		return
+	}
+
+	var list []string
+	if err = json.Unmarshal(body, &list); err != nil {
+		return
+	}
+	for _, name := range list {
+		names[name] = true
+	}
+
+	return
+}
+
+func (h *hostOnlyIfIndex) write(names map[string]bool) (err error) {
+	list := []string{}
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+
+	body, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(h.path), os.ModePerm); err != nil {
+		return
+	}
+
+	return ioutil.WriteFile(h.path, body, 0644)
+}
+
+func (h *hostOnlyIfIndex) add(name string) (err error) {
+	names, err := h.read()
+	if err != nil {
+		return
+	}
+
+	names[name] = true
+	return h.write(names)
+}
+
+func (h *hostOnlyIfIndex) remove(name string) (err error) {
+	names, err := h.read()
+	if err != nil || !names[name] {
+		return
+	}
+
+	delete(names, name)
+	return h.write(names)
+}
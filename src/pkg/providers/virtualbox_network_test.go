@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const hostOnlyIfsOutput = `Name:            vboxnet0
+GUID:            786f6276-656e-4074-8000-0a0027000000
+DHCP:            Disabled
+IPAddress:       10.10.10.1
+NetworkMask:     255.255.255.0
+IPV6Address:
+IPV6NetworkMaskPrefixLength: 0
+HardwareAddress: 0a:00:27:00:00:00
+MediumType:      Ethernet
+Status:          Up
+VBoxNetworkName: HostInterfaceNetworking-vboxnet0
+
+Name:            vboxnet1
+GUID:            786f6276-656e-4174-8000-0a0027000001
+DHCP:            Disabled
+IPAddress:       192.168.56.1
+NetworkMask:     255.255.0.0
+VBoxNetworkName: HostInterfaceNetworking-vboxnet1
+`
+
+func TestParseHostOnlyIfs(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]hostOnlyIf{
+		{Name: "vboxnet0", IP: "10.10.10.1", Mask: "255.255.255.0"},
+		{Name: "vboxnet1", IP: "192.168.56.1", Mask: "255.255.0.0"},
+	}, parseHostOnlyIfs(hostOnlyIfsOutput))
+}
+
+func TestHostOnlyGateway(t *testing.T) {
+	assert := assert.New(t)
+
+	gateway, err := hostOnlyGateway("10.10.20.10")
+	assert.NoError(err)
+	assert.Equal("10.10.20.1", gateway)
+
+	_, err = hostOnlyGateway("denver.local")
+	assert.EqualError(err, "localip denver.local is not a valid IPv4 address")
+}
+
+func TestCheckSubnetConflicts(t *testing.T) {
+	assert := assert.New(t)
+	ifs := parseHostOnlyIfs(hostOnlyIfsOutput)
+	lan := &net.IPNet{IP: net.ParseIP("172.16.5.12"), Mask: net.CIDRMask(24, 32)}
+	vboxnet0 := &net.IPNet{IP: net.ParseIP("10.10.10.1"), Mask: net.CIDRMask(24, 32)}
+
+	vpn := &net.IPNet{IP: net.ParseIP("10.10.0.0"), Mask: net.CIDRMask(16, 32)}
+
+	assert.NoError(checkSubnetConflicts("10.10.20.1", ifs, []*net.IPNet{lan, vboxnet0}, nil))
+
+	assert.EqualError(
+		checkSubnetConflicts("10.10.20.1", ifs, []*net.IPNet{lan, vboxnet0}, []*net.IPNet{vboxnet0, vpn}),
+		"the 10.10.20.0/24 subnet conflicts with the route to 10.10.0.0/16, change localip",
+	)
+	assert.EqualError(
+		checkSubnetConflicts("192.168.56.1", ifs, nil, nil),
+		"the 192.168.56.0/24 subnet conflicts with the host-only interface vboxnet1 (192.168.56.1/255.255.0.0)",
+	)
+	assert.EqualError(
+		checkSubnetConflicts("172.16.5.1", ifs, []*net.IPNet{lan, vboxnet0}, nil),
+		"the 172.16.5.0/24 subnet conflicts with the host network 172.16.5.12/24, change localip",
+	)
+}
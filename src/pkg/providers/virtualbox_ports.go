@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Forwarding rules created by denver are prefixed so that the ones added by hand are left alone
+const portForwardPrefix = "denver-"
+
+// AddPortForward forwards a host port to the VM, live if it is running
+func (v *Virtualbox) AddPortForward(forward PortForward) (err error) {
+	forwards, err := v.checkPortForwardsAvailable()
+	if err != nil {
+		return
+	}
+
+	for _, f := range forwards {
+		if f.Host == forward.Host {
+			return fmt.Errorf("host port %d is already forwarded to %d", f.Host, f.Guest)
+		}
+	}
+
+	return v.natpf(forward.rule())
+}
+
+// RemovePortForward removes a forward added by denver, live if the VM is running
+func (v *Virtualbox) RemovePortForward(forward PortForward) (err error) {
+	forwards, err := v.checkPortForwardsAvailable()
+	if err != nil {
+		return
+	}
+
+	for _, f := range forwards {
+		if f == forward {
+			return v.natpf("delete", forward.ruleName())
+		}
+	}
+
+	return fmt.Errorf("port forward %s not found", forward)
+}
+
+// ListPortForwards returns the forwards added by denver, sorted by host port
+func (v *Virtualbox) ListPortForwards() ([]PortForward, error) {
+	return v.checkPortForwardsAvailable()
+}
+
+func (v *Virtualbox) checkPortForwardsAvailable() ([]PortForward, error) {
+	if exists, err := v.checkIfExists(); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("%s does not exists", v.instance.Name)
+	}
+
+	info, err := v.machineReadableInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePortForwards(info), nil
+}
+
+// syncPortForwards makes the forwards of the stopped VM match the configuration
+func (v *Virtualbox) syncPortForwards() (err error) {
+	info, err := v.machineReadableInfo()
+	if err != nil {
+		return
+	}
+
+	return v.applyPortForwards(parsePortForwards(info))
+}
+
+// applyPortForwards removes and adds the forwards needed to go from current to the configuration
+func (v *Virtualbox) applyPortForwards(current []PortForward) (err error) {
+	wanted, err := ParsePortForwards(v.instance.Ports)
+	if err != nil {
+		return
+	}
+
+	for _, forward := range current {
+		if !containsPortForward(wanted, forward) {
+			log.Printf("Removing port forward %s", forward)
+			if err = v.modifyNatpf("delete", forward.ruleName()); err != nil {
+				return
+			}
+		}
+	}
+
+	for _, forward := range wanted {
+		if !containsPortForward(current, forward) {
+			log.Printf("Adding port forward %s", forward)
+			if err = v.modifyNatpf(forward.rule()); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// natpf changes the forwarding rules of nic1, controlvm is needed while the VM is running
+func (v *Virtualbox) natpf(args ...string) error {
+	isRunning, err := v.checkIfRunning()
+	if err != nil {
+		return err
+	}
+	if !isRunning {
+		return v.modifyNatpf(args...)
+	}
+
+	cmd := append([]string{
+		"VBoxManage", "controlvm", v.instance.Name, "natpf1",
+	}, args...)
+	_, err = v.executor.Execute(cmd)
+	return err
+}
+
+func (v *Virtualbox) modifyNatpf(args ...string) error {
+	cmd := append([]string{
+		"VBoxManage", "modifyvm", v.instance.Name, "--natpf1",
+	}, args...)
+	_, err := v.executor.Execute(cmd)
+	return err
+}
+
+func (p PortForward) ruleName() string {
+	return fmt.Sprintf("%s%d", portForwardPrefix, p.Host)
+}
+
+// rule only binds the host loopback, the VM is already reachable from its host-only IP
+func (p PortForward) rule() string {
+	return fmt.Sprintf("%s,tcp,127.0.0.1,%d,,%d", p.ruleName(), p.Host, p.Guest)
+}
+
+// parsePortForwards reads the `Forwarding(N)="name,proto,hostip,hostport,guestip,guestport"` entries
+func parsePortForwards(info map[string]string) (forwards []PortForward) {
+	for key, value := range info {
+		if !strings.HasPrefix(key, "Forwarding(") {
+			continue
+		}
+
+		fields := strings.Split(value, ",")
+		if len(fields) != 6 || !strings.HasPrefix(fields[0], portForwardPrefix) || fields[1] != "tcp" {
+			continue
+		}
+
+		host, err := strconv.Atoi(fields[3])
+		if err != nil {
+			continue
+		}
+		guest, err := strconv.Atoi(fields[5])
+		if err != nil {
+			continue
+		}
+
+		forwards = append(forwards, PortForward{Host: host, Guest: guest})
+	}
+
+	sort.Slice(forwards, func(i, j int) bool {
+		return forwards[i].Host < forwards[j].Host
+	})
+
+	return
+}
+
+func containsPortForward(forwards []PortForward, forward PortForward) bool {
+	for _, f := range forwards {
+		if f == forward {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minVirtualboxVersion is the first version knowing every modifyvm flag used by denver
+const minVirtualboxVersion = 6
+
+var virtualboxVersionRegexp = regexp.MustCompile(`^([0-9]+)\.([0-9]+)\.([0-9]+)`)
+
+// Preflight checks that VirtualBox is recent enough and that the host can run VMs
+func (v *Virtualbox) Preflight() (err error) {
+	out, err := v.executor.ExecuteWithOptions([]string{"VBoxManage", "--version"}, queryOptions)
+	if err != nil {
+		return fmt.Errorf("unable to run VBoxManage, install VirtualBox %d or newer and add it to your PATH: %s", minVirtualboxVersion, err)
+	}
+
+	if err = checkVirtualboxVersion(out); err != nil {
+		return
+	}
+
+	return checkVirtualization()
+}
+
+func checkVirtualboxVersion(out string) error {
+	// Warnings about the kernel modules may be printed before the version
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	version := strings.TrimSpace(lines[len(lines)-1])
+
+	matches := virtualboxVersionRegexp.FindStringSubmatch(version)
+	if matches == nil {
+		return fmt.Errorf("unable to read the VirtualBox version from %q", version)
+	}
+
+	if major, _ := strconv.Atoi(matches[1]); major < minVirtualboxVersion {
+		return fmt.Errorf("VirtualBox %s is not supported, upgrade to VirtualBox %d or newer", version, minVirtualboxVersion)
+	}
+
+	return nil
+}
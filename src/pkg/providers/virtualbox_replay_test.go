@@ -0,0 +1,203 @@
+package providers
+
+import (
+	"denver/pkg/providers/virtualbox"
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The fixtures of testdata/virtualbox are hand-written and replayed in order, the instance being the
+// one of replayInstance. The hidden --record flag of denver records the commands of a real VirtualBox
+// in the same format, the queries of the probe being left out, a fixture may be started from there
+
+type replayUpdater struct {
+	upToDate bool
+	updated  bool
+}
+
+func (u *replayUpdater) CheckIsUpdated() (bool, error) { return u.upToDate, nil }
+func (u *replayUpdater) Update() error {
+	u.updated = true
+	return nil
+}
+func (u *replayUpdater) GetLocalManifest() (virtualbox.Manifest, error) {
+	return virtualbox.Manifest{Version: "1.2.0"}, nil
+}
+
+const recordedState = `{
+  "version": 1,
+  "vmUuid": "6e3b1c2a-2c1b-4c5e-9a0e-8d3f1f9b2a10",
+  "hostOnlyIf": "vboxnet1",
+  "hostOnlyIfCreated": true,
+  "rootMedium": "a1b2c3d4-0000-4000-8000-000000000001",
+  "userDataMedium": "a1b2c3d4-0000-4000-8000-000000000002",
+  "rbiVersion": "1.2.0",
+  "createdAt": "2020-02-01T10:00:00Z"
+}`
+
+func replayInstance() *structs.InstanceConf {
+	return &structs.InstanceConf{
+		Name:         "denver",
+		Store:        "store",
+		Vcpu:         2,
+		Vmem:         512,
+		Localip:      "10.10.30.10",
+		Userdatasize: 1,
+		Ports:        []string{"8080:80"},
+	}
+}
+
+// getReplayedVirtualbox returns a Virtualbox replaying fixture in a new working directory,
+// files are created in its store beforehand
+func getReplayedVirtualbox(t *testing.T, fixture string, updater VMUpdater, files map[string]string) (*Virtualbox, *executor.ReplayExecutor, string, func()) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := filepath.Join(dir, "store")
+	if err = os.MkdirAll(store, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(store, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e, err := executor.LoadReplayExecutor(filepath.Join("testdata", "virtualbox", fixture), map[string]string{"dir": dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaultHostNetworks := hostNetworks
+	hostNetworks = func() ([]*net.IPNet, []*net.IPNet, error) { return nil, nil, nil }
+
+	v := newVirtualBox(
+		structs.Provider{Name: "local-vb", Hypervisor: TypeVirtualbox},
+		replayInstance(),
+		filepath.Join(store, "stable", "box.vdi"),
+		e,
+		updater,
+		dir,
+	)
+
+	return v, e, dir, func() {
+		hostNetworks = defaultHostNetworks
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestReplayInit(t *testing.T) {
+	assert := assert.New(t)
+	v, e, dir, clean := getReplayedVirtualbox(t, "init.json", &replayUpdater{upToDate: true}, nil)
+	defer clean()
+
+	assert.NoError(v.Init())
+	assert.Empty(e.Remaining())
+
+	state, err := newInstanceStateFile(filepath.Join(dir, "store")).read()
+	assert.NoError(err)
+	assert.Equal("6e3b1c2a-2c1b-4c5e-9a0e-8d3f1f9b2a10", state.VMUUID)
+	assert.Equal("vboxnet1", state.HostOnlyIf)
+	assert.True(state.HostOnlyIfCreated)
+	assert.Equal("a1b2c3d4-0000-4000-8000-000000000002", state.UserDataMedium)
+	assert.Equal("1.2.0", state.RBIVersion)
+
+	interrupted, err := v.initJournal.exists()
+	assert.NoError(err)
+	assert.False(interrupted)
+}
+
+func TestReplayInitRollsBackOnFailure(t *testing.T) {
+	assert := assert.New(t)
+	v, e, dir, clean := getReplayedVirtualbox(t, "init-rollback.json", &replayUpdater{upToDate: true}, nil)
+	defer clean()
+
+	err := v.Init()
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "VERR_FILE_NOT_FOUND")
+		assert.Contains(err.Error(), filepath.Join(dir, "store", "stable", "box.vdi"))
+	}
+	assert.Empty(e.Remaining())
+
+	interrupted, err := v.initJournal.exists()
+	assert.NoError(err)
+	assert.False(interrupted)
+}
+
+func TestReplayStart(t *testing.T) {
+	assert := assert.New(t)
+	v, e, _, clean := getReplayedVirtualbox(t, "start.json", &replayUpdater{upToDate: true}, map[string]string{
+		"state.json": recordedState,
+	})
+	defer clean()
+
+	assert.NoError(v.Start())
+	assert.Empty(e.Remaining())
+	assert.False(v.Resumed())
+}
+
+func TestReplayStop(t *testing.T) {
+	assert := assert.New(t)
+	v, e, _, clean := getReplayedVirtualbox(t, "stop.json", &replayUpdater{upToDate: true}, nil)
+	defer clean()
+
+	var stopped bool
+	v.AddPreStopAction(func() error {
+		stopped = true
+		return nil
+	})
+
+	assert.NoError(v.Stop())
+	assert.Empty(e.Remaining())
+	assert.True(stopped)
+}
+
+func TestReplayUnregister(t *testing.T) {
+	assert := assert.New(t)
+	v, e, dir, clean := getReplayedVirtualbox(t, "unregister.json", &replayUpdater{upToDate: true}, map[string]string{
+		"state.json": recordedState,
+	})
+	defer clean()
+
+	assert.NoError(v.Unregister())
+	assert.Empty(e.Remaining())
+
+	state, err := newInstanceStateFile(filepath.Join(dir, "store")).read()
+	assert.NoError(err)
+	assert.Nil(state)
+}
+
+func TestReplayUpdate(t *testing.T) {
+	assert := assert.New(t)
+	updater := &replayUpdater{}
+	// The userdata disk is kept, it is attached again to the new VM
+	v, e, _, clean := getReplayedVirtualbox(t, "update.json", updater, map[string]string{
+		"state.json":   recordedState,
+		"userdata.vdi": "",
+	})
+	defer clean()
+
+	updated, err := v.Update()
+	assert.NoError(err)
+	assert.True(updated)
+	assert.True(updater.updated)
+	assert.Empty(e.Remaining())
+}
+
+func TestReplayFailsOnUnexpectedCommands(t *testing.T) {
+	assert := assert.New(t)
+	v, _, _, clean := getReplayedVirtualbox(t, "stop.json", &replayUpdater{upToDate: true}, nil)
+	defer clean()
+
+	err := v.Unregister()
+	assert.EqualError(err, "unexpected command VBoxManage showvminfo --machinereadable denver, testdata/virtualbox/stop.json expects VBoxManage list vms")
+}
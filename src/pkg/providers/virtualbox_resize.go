@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// Resize applies the configured CPUs and memory to the VM, which must be powered off
+func (v *Virtualbox) Resize() (changes []ResourceChange, err error) {
+	if exists, err := v.checkIfExists(); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("%s does not exists", v.instance.Name)
+	}
+
+	changes, err = v.resourceDrift()
+	if err != nil || len(changes) == 0 {
+		return
+	}
+
+	if isRunning, err := v.checkIfRunning(); err != nil {
+		return nil, err
+	} else if isRunning {
+		return nil, fmt.Errorf("%s is running, stop it to apply the new resources", v.instance.Name)
+	}
+
+	for _, change := range changes {
+		switch change.Name {
+		case "vcpu":
+			err = v.setCPU(change.To)
+		case "vmem":
+			err = v.setMEM(change.To)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// reconcile is run before each start so that config.yml changes are honoured
+func (v *Virtualbox) reconcile() (err error) {
+	changes, err := v.Resize()
+	for _, change := range changes {
+		log.Printf("%s changed from %s to %s", change.Name, change.From, change.To)
+	}
+	if err != nil {
+		return
+	}
+
+	if err = v.logInstanceDrift(); err != nil {
+		return
+	}
+
+	return v.syncPortForwards()
+}
+
+func (v *Virtualbox) resourceDrift() (changes []ResourceChange, err error) {
+	info, err := v.vmInfo()
+	if err != nil {
+		return
+	}
+
+	resources := []struct {
+		name    string
+		current int
+		value   int
+	}{
+		{"vcpu", info.CPUs, v.instance.Vcpu},
+		{"vmem", info.Memory, v.instance.Vmem},
+	}
+
+	for _, resource := range resources {
+		if resource.value <= 0 {
+			continue
+		}
+
+		if resource.current <= 0 {
+			return nil, fmt.Errorf("unable to retrieve %s from the Virtual Machine", resource.name)
+		}
+
+		if resource.current != resource.value {
+			changes = append(changes, ResourceChange{
+				Name: resource.name,
+				From: strconv.Itoa(resource.current),
+				To:   strconv.Itoa(resource.value),
+			})
+		}
+	}
+
+	return
+}
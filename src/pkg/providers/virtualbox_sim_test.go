@@ -0,0 +1,327 @@
+package providers
+
+import (
+	"context"
+	"denver/pkg/util"
+	"denver/pkg/util/executor"
+	"denver/structs"
+	"denver/test/vboxsim"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getSimulatedVirtualbox returns a Virtualbox driving a simulator, the RBI is already downloaded
+func getSimulatedVirtualbox(t *testing.T) (*Virtualbox, *vboxsim.Simulator, string, func()) {
+	dir, err := ioutil.TempDir("", "vboxsim")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boxPath := filepath.Join(dir, "store", "stable", "box.vdi")
+	if err = os.MkdirAll(filepath.Dir(boxPath), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(boxPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defaultHostNetworks := hostNetworks
+	hostNetworks = func() ([]*net.IPNet, []*net.IPNet, error) { return nil, nil, nil }
+
+	sim := vboxsim.New()
+	v := newVirtualBox(
+		structs.Provider{Name: "local-vb", Hypervisor: TypeVirtualbox},
+		replayInstance(),
+		boxPath,
+		sim,
+		&replayUpdater{upToDate: true},
+		dir,
+	)
+
+	return v, sim, dir, func() {
+		hostNetworks = defaultHostNetworks
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestSimulatedLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, dir, clean := getSimulatedVirtualbox(t)
+	defer clean()
+
+	assert.NoError(v.Init())
+	vm := sim.VM("denver")
+	if assert.NotNil(vm) {
+		assert.Equal("poweroff", vm.State)
+		assert.Equal(2, vm.CPUs)
+		assert.Equal(512, vm.Memory)
+		assert.Equal("vboxnet0", vm.HostOnlyIfs[2])
+		assert.Equal([]string{"denver-8080,tcp,127.0.0.1,8080,,80"}, vm.Forwards)
+		assert.Len(vm.Attachments, 2)
+	}
+	assert.Equal([]vboxsim.HostOnlyIf{{Name: "vboxnet0", IP: "10.10.30.1", Mask: "255.255.255.0"}}, sim.HostOnlyIfs())
+
+	assert.NoError(v.Start())
+	assert.True(sim.VM("denver").Running())
+	assert.EqualError(v.Start(), "denver is running")
+
+	assert.NoError(v.Stop())
+	assert.False(sim.VM("denver").Running())
+	assert.EqualError(v.Stop(), "denver is not running")
+
+	assert.NoError(v.Unregister())
+	assert.Nil(sim.VM("denver"))
+	assert.Empty(sim.HostOnlyIfs())
+	assert.Empty(sim.Media())
+
+	// The disks are only closed, the projects of the userdata disk are kept
+	exists, err := util.Exists(filepath.Join(dir, "store", "userdata.vdi"))
+	assert.NoError(err)
+	assert.True(exists)
+}
+
+func TestSimulatedUnregisterDeletesTheSnapshots(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, dir, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	index := filepath.Join(dir, "store", "snapshots.json")
+
+	assert.NoError(v.Init())
+	assert.NoError(v.SaveSnapshot("first"))
+	assert.NoError(v.SaveSnapshot("second"))
+	exists, err := util.Exists(index)
+	assert.NoError(err)
+	assert.True(exists)
+	sim.FailOn("VBoxManage: error: Snapshot operation failed\n",
+		"VBoxManage", "snapshot", "denver", "delete", "first")
+
+	// Nothing is unregistered as long as a snapshot is left
+	assert.EqualError(v.Unregister(), "unable to delete the snapshot first of denver: exit status 1\nVBoxManage: error: Snapshot operation failed\n\n")
+	if vm := sim.VM("denver"); assert.NotNil(vm) {
+		assert.Equal([]string{"first"}, vm.Snapshots)
+	}
+
+	assert.NoError(v.Unregister())
+	assert.Nil(sim.VM("denver"))
+	exists, err = util.Exists(index)
+	assert.NoError(err)
+	assert.False(exists)
+}
+
+func TestSimulatedStartAppliesTheConfiguration(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, _, clean := getSimulatedVirtualbox(t)
+	defer clean()
+
+	assert.NoError(v.Init())
+
+	v.instance.Vcpu = 4
+	v.instance.Ports = []string{"8443:443"}
+	assert.NoError(v.Start())
+
+	vm := sim.VM("denver")
+	assert.Equal(4, vm.CPUs)
+	assert.Equal([]string{"denver-8443,tcp,127.0.0.1,8443,,443"}, vm.Forwards)
+}
+
+func TestSimulatedInitReusesTheHostOnlyInterface(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, _, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	sim.AddHostOnlyIf("vboxnet3", "10.10.30.1", "255.255.255.0")
+
+	assert.NoError(v.Init())
+	assert.Equal("vboxnet3", sim.VM("denver").HostOnlyIfs[2])
+
+	// It was there before the init, it is kept
+	assert.NoError(v.Unregister())
+	assert.Len(sim.HostOnlyIfs(), 1)
+}
+
+func TestSimulatedUnregisterKeepsASharedHostOnlyInterface(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, dir, clean := getSimulatedVirtualbox(t)
+	defer clean()
+
+	instance := replayInstance()
+	instance.Name = "legacy"
+	instance.Store = filepath.Join("store", "legacy")
+	instance.Localip = "10.10.30.20"
+	boxPath := filepath.Join(dir, instance.Store, "stable", "box.vdi")
+	if err := os.MkdirAll(filepath.Dir(boxPath), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(boxPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	other := newVirtualBox(v.provider, instance, boxPath, sim, &replayUpdater{upToDate: true}, dir)
+
+	assert.NoError(v.Init())
+	assert.NoError(other.Init())
+	if vm := sim.VM("legacy"); assert.NotNil(vm) {
+		assert.Equal("vboxnet0", vm.HostOnlyIfs[2])
+	}
+
+	// The interface has been created for denver, legacy still uses it
+	assert.NoError(v.Unregister())
+	assert.Len(sim.HostOnlyIfs(), 1)
+
+	assert.NoError(other.Unregister())
+	removed, err := v.PruneNetworks()
+	assert.NoError(err)
+	assert.Equal([]string{"vboxnet0"}, removed)
+	assert.Empty(sim.HostOnlyIfs())
+}
+
+func TestSimulatedInitRollsBackOnFailure(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, dir, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	sim.FailOn("VBoxManage: error: Could not find file for the medium (VERR_FILE_NOT_FOUND)\n",
+		"VBoxManage", "storageattach", "denver", "--storagectl", "SAS", "--port", "1")
+
+	err := v.Init()
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "VERR_FILE_NOT_FOUND")
+	}
+
+	assert.Nil(sim.VM("denver"))
+	assert.Empty(sim.HostOnlyIfs())
+	assert.Empty(sim.Media())
+	// The userdata disk had been created by this init, it is removed
+	exists, err := util.Exists(filepath.Join(dir, "store", "userdata.vdi"))
+	assert.NoError(err)
+	assert.False(exists)
+
+	assert.NoError(v.Init())
+}
+
+// interruptedExecutor is canceled by Ctrl-C instead of running the command starting with prefix,
+// only the cleanup commands are run afterwards
+type interruptedExecutor struct {
+	executor.Executor
+
+	prefix   string
+	canceled bool
+}
+
+func (e *interruptedExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, executor.Options{})
+}
+
+func (e *interruptedExecutor) ExecuteWithOptions(args []string, options executor.Options) (string, error) {
+	if strings.HasPrefix(executor.Quote(args), e.prefix) {
+		e.canceled = true
+	}
+	if e.canceled && !options.Cleanup {
+		return "", &executor.Error{Args: args, ExitCode: -1, Err: context.Canceled}
+	}
+
+	return e.Executor.ExecuteWithOptions(args, options)
+}
+
+func TestSimulatedInitRollsBackOnceInterrupted(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, _, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	v.executor = &interruptedExecutor{
+		Executor: sim,
+		prefix:   executor.Quote([]string{"VBoxManage", "storageattach", "denver", "--storagectl", "SAS", "--port", "1"}),
+	}
+
+	err := v.Init()
+	assert.True(executor.IsCanceled(err))
+
+	assert.Nil(sim.VM("denver"))
+	assert.Empty(sim.HostOnlyIfs())
+	assert.Empty(sim.Media())
+}
+
+func TestSimulatedInitCanBeResumed(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, _, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	sim.FailOn("VBoxManage: error: Code E_ACCESSDENIED\n", "VBoxManage", "storagectl")
+	sim.FailOn("VBoxManage: error: Code E_ACCESSDENIED\n", "VBoxManage", "hostonlyif", "remove")
+
+	err := v.Init()
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "run init --resume")
+	}
+	assert.EqualError(v.Init(), "denver has been partially initialized, run init --resume")
+
+	assert.NoError(v.ResumeInit())
+	vm := sim.VM("denver")
+	if assert.NotNil(vm) {
+		assert.Equal([]string{"SAS"}, vm.Controllers)
+		assert.Len(vm.Attachments, 2)
+	}
+
+	state, err := v.InstanceState()
+	assert.NoError(err)
+	assert.Equal(vm.UUID, state.VMUUID)
+	assert.False(state.Discovered)
+}
+
+func TestSimulatedPruneOnlyRemovesTheInterfacesCreatedByDenver(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, _, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	// The default interface of VirtualBox looks like the ones of denver
+	sim.AddHostOnlyIf("vboxnet0", "192.168.56.1", "255.255.255.0")
+	sim.AddHostOnlyIf("vboxnet5", "10.10.50.1", "255.255.255.0")
+	assert.NoError(v.createdHostOnlyIfs.add("vboxnet5"))
+	assert.NoError(v.createdHostOnlyIfs.add("vboxnet9"))
+
+	assert.NoError(v.Init())
+	created := sim.VM("denver").HostOnlyIfs[2]
+
+	removed, err := v.PruneNetworks()
+	assert.NoError(err)
+	assert.Equal([]string{"vboxnet5"}, removed)
+
+	var names []string
+	for _, i := range sim.HostOnlyIfs() {
+		names = append(names, i.Name)
+	}
+	assert.ElementsMatch([]string{"vboxnet0", created}, names)
+
+	recorded, err := v.createdHostOnlyIfs.read()
+	assert.NoError(err)
+	assert.Equal(map[string]bool{created: true}, recorded)
+
+	assert.NoError(v.Unregister())
+	recorded, err = v.createdHostOnlyIfs.read()
+	assert.NoError(err)
+	assert.Empty(recorded)
+}
+
+func TestProbeQueriesAreNotRecorded(t *testing.T) {
+	assert := assert.New(t)
+	v, sim, dir, clean := getSimulatedVirtualbox(t)
+	defer clean()
+	recorder := executor.NewRecordingExecutor(sim, nil)
+	v.executor = recorder
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	probe, err := NewProbe(ctx, nil, nil)
+	assert.NoError(err)
+	assert.NoError(probe.Start(v))
+	_, err = v.checkIfRunning()
+	assert.NoError(err)
+
+	fixture := filepath.Join(dir, "fixture.json")
+	assert.NoError(recorder.Save(fixture))
+	replay, err := executor.LoadReplayExecutor(fixture, nil)
+	assert.NoError(err)
+	assert.Equal([]executor.Interaction{{
+		Args: []string{"VBoxManage", "list", "runningvms"},
+	}}, replay.Remaining())
+}
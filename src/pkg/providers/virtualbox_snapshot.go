@@ -0,0 +1,203 @@
+package providers
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var snapshotNameRegexp = regexp.MustCompile(`^SnapshotName(-[0-9]+)*="(.*)"$`)
+
+// SaveSnapshot takes a snapshot of the VM, live if it is running
+func (v *Virtualbox) SaveSnapshot(name string) (err error) {
+	if err = v.checkSnapshotsAvailable(); err != nil {
+		return
+	}
+
+	names, err := v.snapshotNames()
+	if err != nil {
+		return
+	}
+	for _, n := range names {
+		if n == name {
+			return fmt.Errorf("snapshot %s already exists", name)
+		}
+	}
+
+	manifest, err := v.updater.GetLocalManifest()
+	if err != nil {
+		return
+	}
+
+	cmd := []string{
+		"VBoxManage", "snapshot", v.instance.Name,
+		"take", name,
+		"--description", fmt.Sprintf("Taken by denver, RBI %s", manifest.Version),
+	}
+	if isRunning, err := v.checkIfRunning(); err != nil {
+		return err
+	} else if isRunning {
+		cmd = append(cmd, "--live")
+	}
+
+	if _, err = v.executor.ExecuteWithOptions(cmd, progressOptions); err != nil || v.dryRun {
+		return
+	}
+
+	return v.snapshots.add(Snapshot{
+		Name:       name,
+		Date:       time.Now(),
+		RBIVersion: manifest.Version,
+	})
+}
+
+// ListSnapshots returns the snapshots known by VirtualBox, in creation order
+func (v *Virtualbox) ListSnapshots() (snapshots []Snapshot, err error) {
+	if err = v.checkSnapshotsAvailable(); err != nil {
+		return
+	}
+
+	names, err := v.snapshotNames()
+	if err != nil {
+		return
+	}
+
+	index, err := v.snapshots.read()
+	if err != nil {
+		return
+	}
+
+	for _, name := range names {
+		snapshot, ok := index[name]
+		if !ok {
+			// Taken outside of denver, the metadata are unknown
+			snapshot = Snapshot{Name: name}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return
+}
+
+// RestoreSnapshot restores a snapshot, a running VM is powered off first when forced
+func (v *Virtualbox) RestoreSnapshot(name string, force bool) (err error) {
+	if err = v.checkSnapshotExists(name); err != nil {
+		return
+	}
+
+	isRunning, err := v.checkIfRunning()
+	if err != nil {
+		return
+	}
+	if isRunning {
+		if !force {
+			return fmt.Errorf("%s is running", v.instance.Name)
+		}
+
+		if err = v.PowerOff(); err != nil {
+			return
+		}
+	}
+
+	cmd := []string{
+		"VBoxManage", "snapshot", v.instance.Name,
+		"restore", name,
+	}
+	_, err = v.executor.ExecuteWithOptions(cmd, progressOptions)
+	return
+}
+
+// DeleteSnapshot deletes a snapshot, the current state of the VM is kept
+func (v *Virtualbox) DeleteSnapshot(name string) (err error) {
+	if err = v.checkSnapshotExists(name); err != nil {
+		return
+	}
+
+	cmd := []string{
+		"VBoxManage", "snapshot", v.instance.Name,
+		"delete", name,
+	}
+	if _, err = v.executor.ExecuteWithOptions(cmd, progressOptions); err != nil || v.dryRun {
+		return
+	}
+
+	return v.snapshots.remove(name)
+}
+
+// deleteSnapshots deletes all the snapshots of the VM, the newest first
+func (v *Virtualbox) deleteSnapshots() (err error) {
+	names, err := v.snapshotNames()
+	if err != nil {
+		return
+	}
+
+	for i := len(names) - 1; i >= 0; i-- {
+		log.Printf("Deleting the snapshot %s of %s", names[i], v.instance.Name)
+
+		cmd := []string{
+			"VBoxManage", "snapshot", v.instance.Name,
+			"delete", names[i],
+		}
+		if _, err = v.executor.ExecuteWithOptions(cmd, progressOptions); err != nil {
+			return fmt.Errorf("unable to delete the snapshot %s of %s: %s", names[i], v.instance.Name, err)
+		}
+	}
+
+	return
+}
+
+func (v *Virtualbox) checkSnapshotsAvailable() error {
+	if exists, err := v.checkIfExists(); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("%s does not exists", v.instance.Name)
+	}
+
+	return nil
+}
+
+func (v *Virtualbox) checkSnapshotExists(name string) (err error) {
+	if err = v.checkSnapshotsAvailable(); err != nil {
+		return
+	}
+
+	names, err := v.snapshotNames()
+	if err != nil {
+		return
+	}
+	for _, n := range names {
+		if n == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("snapshot %s not found", name)
+}
+
+func (v *Virtualbox) snapshotNames() (names []string, err error) {
+	cmd := []string{
+		"VBoxManage", "snapshot", v.instance.Name,
+		"list", "--machinereadable",
+	}
+	out, err := v.executor.ExecuteWithOptions(cmd, queryOptions)
+	if err != nil {
+		// VBoxManage fails when there is nothing to list
+		if strings.Contains(err.Error(), "does not have any snapshots") {
+			return nil, nil
+		}
+		return
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		matches := snapshotNameRegexp.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if len(matches) == 3 {
+			names = append(names, matches[2])
+		}
+	}
+
+	return
+}
@@ -0,0 +1,126 @@
+package providers
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// InstanceState returns the recorded state of the instance, rebuilt from showvminfo when
+// state.json is missing, for VMs created by older versions for instance
+func (v *Virtualbox) InstanceState() (state *InstanceState, err error) {
+	if state, err = v.instanceStates.read(); err != nil || state != nil {
+		return
+	}
+
+	info, err := v.machineReadableInfo()
+	if err != nil {
+		return
+	}
+
+	return &InstanceState{
+		Version:    InstanceStateVersion,
+		VMUUID:     info["UUID"],
+		HostOnlyIf: info["hostonlyadapter2"],
+		// Older versions always created the interface
+		HostOnlyIfCreated: info["hostonlyadapter2"] != "",
+		RootMedium:        mediumFromInfo(info, "0"),
+		UserDataMedium:    mediumFromInfo(info, "1"),
+		Discovered:        true,
+	}, nil
+}
+
+// InstanceDrift lists the differences between the recorded state and the VM
+func (v *Virtualbox) InstanceDrift() (drift []string, err error) {
+	recorded, err := v.instanceStates.read()
+	if err != nil || recorded == nil {
+		return
+	}
+
+	info, err := v.machineReadableInfo()
+	if err != nil {
+		return
+	}
+
+	return instanceDrift(recorded, info), nil
+}
+
+// recordInstanceState is called once the VM has been fully created
+func (v *Virtualbox) recordInstanceState(progress *initProgress) (err error) {
+	info, err := v.machineReadableInfo()
+	if err != nil {
+		return
+	}
+
+	manifest, err := v.updater.GetLocalManifest()
+	if err != nil {
+		return
+	}
+
+	return v.instanceStates.write(&InstanceState{
+		VMUUID:            info["UUID"],
+		HostOnlyIf:        progress.HostOnlyIf,
+		HostOnlyIfCreated: progress.HostOnlyIfCreated,
+		RootMedium:        mediumFromInfo(info, "0"),
+		UserDataMedium:    mediumFromInfo(info, "1"),
+		RBIVersion:        manifest.Version,
+		CreatedAt:         time.Now(),
+	})
+}
+
+// logInstanceDrift warns about the changes made to the VM outside of denver
+func (v *Virtualbox) logInstanceDrift() (err error) {
+	drift, err := v.InstanceDrift()
+	for _, d := range drift {
+		log.Printf("%s has been changed outside of denver: %s", v.instance.Name, d)
+	}
+
+	return
+}
+
+func instanceDrift(recorded *InstanceState, info map[string]string) (drift []string) {
+	checks := []struct {
+		name, recorded, current string
+	}{
+		{"VM UUID", recorded.VMUUID, info["UUID"]},
+		{"host-only interface", recorded.HostOnlyIf, info["hostonlyadapter2"]},
+	}
+	// Once a snapshot is taken, differencing disks are attached instead of the recorded ones
+	if info["CurrentSnapshotUUID"] == "" {
+		checks = append(checks, []struct {
+			name, recorded, current string
+		}{
+			{"root disk", recorded.RootMedium, mediumFromInfo(info, "0")},
+			{"userdata disk", recorded.UserDataMedium, mediumFromInfo(info, "1")},
+		}...)
+	}
+
+	for _, check := range checks {
+		if check.recorded != check.current {
+			drift = append(drift, fmt.Sprintf("%s is %s instead of %s", check.name, orNone(check.current), orNone(check.recorded)))
+		}
+	}
+
+	return
+}
+
+// mediumFromInfo returns the UUID of the disk attached to a port of the SAS controller
+func mediumFromInfo(info map[string]string, port string) string {
+	if uuid := info[fmt.Sprintf("SAS-ImageUUID-%s-0", port)]; uuid != "" {
+		return uuid
+	}
+
+	if path := info[fmt.Sprintf("SAS-%s-0", port)]; path != "none" {
+		return path
+	}
+
+	return ""
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+
+	return value
+}
@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"fmt"
+)
+
+// Suspend saves the state of the running VM to disk then stops it
+func (v *Virtualbox) Suspend() error {
+	if exists, err := v.checkIfExists(); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("%s does not exists", v.instance.Name)
+	}
+
+	if isRunning, err := v.checkIfRunning(); err != nil {
+		return err
+	} else if !isRunning {
+		return fmt.Errorf("%s is not running", v.instance.Name)
+	}
+
+	if err := v.run(PreStop); err != nil {
+		return err
+	}
+
+	cmd := []string{
+		"VBoxManage",
+		"controlvm",
+		v.instance.Name,
+		"savestate",
+	}
+	v.expect(PostStop)
+	if _, err := v.executor.ExecuteWithOptions(cmd, progressOptions); err != nil {
+		v.unexpect(PostStop)
+		return err
+	}
+
+	return nil
+}
+
+// IsSaved checks whether the VM has been suspended
+func (v *Virtualbox) IsSaved() (bool, error) {
+	if exists, err := v.checkIfExists(); err != nil || !exists {
+		return false, err
+	}
+
+	info, err := v.vmInfo()
+	if err != nil {
+		return false, err
+	}
+
+	return info.State == "saved", nil
+}
+
+// Resumed tells whether the last Start restored a saved state
+func (v *Virtualbox) Resumed() bool {
+	return v.resumed
+}
@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMachineReadable(t *testing.T) {
+	assert := assert.New(t)
+
+	info := parseMachineReadable(`name="denver"
+memory=2048
+cpus=2
+"SAS-0-0"="/home/j.doe/denver/store/stable/box.vdi"
+description="a=b"
+`)
+
+	assert.Equal("denver", info["name"])
+	assert.Equal("2048", info["memory"])
+	assert.Equal("2", info["cpus"])
+	assert.Equal("/home/j.doe/denver/store/stable/box.vdi", info["SAS-0-0"])
+	assert.Equal("a=b", info["description"])
+}
+
+func TestParsePortForwardsOnlyKeepsDenverRules(t *testing.T) {
+	assert := assert.New(t)
+
+	info := parseMachineReadable(`Forwarding(0)="denver-8080,tcp,127.0.0.1,8080,,80"
+Forwarding(1)="manual,tcp,,2222,,22"
+Forwarding(2)="denver-443,tcp,127.0.0.1,443,,8443"
+Forwarding(3)="denver-53,udp,127.0.0.1,53,,53"
+`)
+
+	assert.Equal([]PortForward{
+		{Host: 443, Guest: 8443},
+		{Host: 8080, Guest: 80},
+	}, parsePortForwards(info))
+}
+
+func TestParsePortForward(t *testing.T) {
+	assert := assert.New(t)
+
+	forward, err := ParsePortForward("8080:80")
+	assert.NoError(err)
+	assert.Equal(PortForward{Host: 8080, Guest: 80}, forward)
+	assert.Equal("8080:80", forward.String())
+	assert.Equal("denver-8080,tcp,127.0.0.1,8080,,80", forward.rule())
+
+	_, err = ParsePortForward("8080")
+	assert.EqualError(err, "invalid port forward 8080, expected <host>:<guest>")
+
+	_, err = ParsePortForward("8080:70000")
+	assert.EqualError(err, "invalid port forward 8080:70000, 70000 is not a valid port")
+}
+
+func TestParseVMInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	info := parseVMInfo(`name="denver"
+UUID="6e3b1c2a-2c1b-4c5e-9a0e-8d3f1f9b2a10"
+memory=2048
+cpus=2
+VMState="running"
+VMStateChangeTime="2020-02-01T10:00:00.000000000"
+storagecontrollername0="SAS"
+"SAS-0-0"="/home/j.doe/denver/store/stable/box.vdi"
+"SAS-ImageUUID-0-0"="a1"
+"SAS-1-0"="/home/j.doe/denver/store/userdata.vdi"
+"SAS-ImageUUID-1-0"="b2"
+nic1="nat"
+macaddress1="080027A1B2C3"
+nic2="hostonly"
+hostonlyadapter2="vboxnet0"
+macaddress2="080027D4E5F6"
+nic3="none"
+SnapshotName="first"
+SnapshotName-1="second"
+`, time.Date(2020, 2, 1, 11, 30, 0, 0, time.UTC))
+
+	assert.Equal("denver", info.Name)
+	assert.Equal("running", info.State)
+	assert.Equal("1h30m0s", info.Uptime)
+	assert.Equal(2, info.CPUs)
+	assert.Equal(2048, info.Memory)
+	assert.Equal([]NIC{
+		{Index: 1, Type: "nat", MAC: "080027A1B2C3"},
+		{Index: 2, Type: "hostonly", Network: "vboxnet0", MAC: "080027D4E5F6"},
+	}, info.NICs)
+	assert.Equal([]StorageAttachment{
+		{Controller: "SAS", Port: 0, Device: 0, Medium: "/home/j.doe/denver/store/stable/box.vdi", UUID: "a1"},
+		{Controller: "SAS", Port: 1, Device: 0, Medium: "/home/j.doe/denver/store/userdata.vdi", UUID: "b2"},
+	}, info.Storage)
+	assert.Equal([]string{"first", "second"}, info.Snapshots)
+}
+
+func TestCheckVirtualboxVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(checkVirtualboxVersion("6.1.2r135662\n"))
+	assert.NoError(checkVirtualboxVersion("WARNING: The vboxdrv kernel module is not loaded.\n7.0.4r154605\n"))
+	assert.EqualError(checkVirtualboxVersion("5.2.34r133893\n"), "VirtualBox 5.2.34r133893 is not supported, upgrade to VirtualBox 6 or newer")
+	assert.EqualError(checkVirtualboxVersion(""), `unable to read the VirtualBox version from ""`)
+}
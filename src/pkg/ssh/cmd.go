@@ -7,22 +7,21 @@ import (
 )
 
 type cmd struct {
-	client *ssh.Client
+	newSession func() (*ssh.Session, error)
 }
 
 // Cmd executes a command over an SSH connection
 func (s *SSH) Cmd(command string) (out string, err error) {
-	client, err := s.connect()
-	if err != nil {
-		return
-	}
-	defer client.Close()
+	return (&cmd{newSession: s.conn.newSession}).cmd(command)
+}
 
-	return (&cmd{client: client}).cmd(command)
+// CombinedOutput executes a command over an SSH connection, out holds both its standard and error outputs
+func (s *SSH) CombinedOutput(command string) (out string, err error) {
+	return (&cmd{newSession: s.conn.newSession}).combinedOutput(command)
 }
 
 func (c *cmd) cmd(cmd string) (out string, err error) {
-	session, err := c.client.NewSession()
+	session, err := c.newSession()
 	if err != nil {
 		return
 	}
@@ -44,3 +43,14 @@ func (c *cmd) cmd(cmd string) (out string, err error) {
 
 	return
 }
+
+func (c *cmd) combinedOutput(cmd string) (out string, err error) {
+	session, err := c.newSession()
+	if err != nil {
+		return
+	}
+	defer session.Close()
+
+	output, err := session.CombinedOutput(cmd)
+	return string(output), err
+}
@@ -0,0 +1,123 @@
+package ssh
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	keepAliveInterval = 5 * time.Second
+	// A connection whose keepalive is not answered in time is dropped, the VM may be gone
+	keepAliveTimeout = 3 * time.Second
+)
+
+// connection is the SSH client shared by the commands, copies and terminals, each of them
+// opening its own session on it. A broken client is dropped and dialed again on next use.
+type connection struct {
+	mutex  sync.Mutex
+	client *ssh.Client
+	dial   func() (*ssh.Client, error)
+}
+
+func newConnection(dial func() (*ssh.Client, error)) *connection {
+	return &connection{
+		dial: dial,
+	}
+}
+
+// newSession opens a session, the client is dialed again once when the current one is broken
+func (c *connection) newSession() (session *ssh.Session, err error) {
+	client, err := c.get()
+	if err != nil {
+		return
+	}
+
+	if session, err = client.NewSession(); err == nil {
+		return
+	}
+
+	c.drop(client)
+	if client, err = c.get(); err != nil {
+		return
+	}
+
+	return client.NewSession()
+}
+
+// close closes the current client, the next session dials a new one
+func (c *connection) close() {
+	c.mutex.Lock()
+	client := c.client
+	c.mutex.Unlock()
+
+	if client != nil {
+		c.drop(client)
+	}
+}
+
+func (c *connection) get() (*ssh.Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	client, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+
+	go c.keepAlive(client)
+	go func() {
+		_ = client.Wait()
+		c.drop(client)
+	}()
+
+	return client, nil
+}
+
+// drop closes client and forgets it, unless it has already been replaced
+func (c *connection) drop(client *ssh.Client) {
+	c.mutex.Lock()
+	if c.client == client {
+		c.client = nil
+	}
+	c.mutex.Unlock()
+
+	_ = client.Close()
+}
+
+func (c *connection) keepAlive(client *ssh.Client) {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.mutex.Lock()
+		current := c.client == client
+		c.mutex.Unlock()
+		if !current {
+			return
+		}
+
+		answered := make(chan error, 1)
+		go func() {
+			_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+			answered <- err
+		}()
+
+		select {
+		case err := <-answered:
+			if err == nil {
+				continue
+			}
+		case <-time.After(keepAliveTimeout):
+		}
+
+		c.drop(client)
+		return
+	}
+}
@@ -12,18 +12,12 @@ import (
 )
 
 type copy struct {
-	client       *ssh.Client
+	newSession   func() (*ssh.Session, error)
 	remoteBinary string
 }
 
 // Copy a file to a remote location
 func (s *SSH) Copy(localFile, remotePath string, mode os.FileMode) (err error) {
-	client, err := s.connect()
-	if err != nil {
-		return
-	}
-	defer client.Close()
-
 	f, err := os.Open(localFile)
 	if err != nil {
 		return
@@ -31,7 +25,7 @@ func (s *SSH) Copy(localFile, remotePath string, mode os.FileMode) (err error) {
 	defer f.Close()
 
 	return (&copy{
-		client:       client,
+		newSession:   s.conn.newSession,
 		remoteBinary: "scp",
 	}).copy(
 		f,
@@ -47,7 +41,7 @@ func (c *copy) copy(file *os.File, mode os.FileMode, destination string) (err er
 		return
 	}
 
-	session, err := c.client.NewSession()
+	session, err := c.newSession()
 	if err != nil {
 		return
 	}
@@ -44,22 +44,24 @@ const (
 		"-----END RSA PRIVATE KEY-----"
 )
 
-// SSH implements ssh protocol
+// SSH implements ssh protocol, the connection is kept open and shared by the commands
 type SSH struct {
 	IP      string
-	port    string
+	Port    string
 	User    string
 	keyPath string
+	conn    *connection
 }
 
 // NewSSH returns a pointer to SSH
-func NewSSH(ip string, workingDirectory string) (*SSH, error) {
+func NewSSH(ip, user string, port int, workingDirectory string) (*SSH, error) {
 	s := &SSH{
 		IP:      ip,
-		port:    "22",
-		User:    "ldevuser",
+		Port:    fmt.Sprintf("%d", port),
+		User:    user,
 		keyPath: filepath.Join(workingDirectory, ".ssh"),
 	}
+	s.conn = newConnection(s.connect)
 
 	if err := s.init(); err != nil {
 		return nil, err
@@ -68,6 +70,13 @@ func NewSSH(ip string, workingDirectory string) (*SSH, error) {
 	return s, nil
 }
 
+// Close closes the shared connection, it is opened again when needed
+func (s *SSH) Close() {
+	if s.conn != nil {
+		s.conn.close()
+	}
+}
+
 func (s *SSH) connect() (client *ssh.Client, err error) {
 	key, err := ioutil.ReadFile(s.getPrivKey())
 	if err != nil {
@@ -87,7 +96,7 @@ func (s *SSH) connect() (client *ssh.Client, err error) {
 		Timeout:         time.Duration(1) * time.Second,
 	}
 
-	client, err = ssh.Dial("tcp", fmt.Sprintf("%s:%s", s.IP, s.port), config)
+	client, err = ssh.Dial("tcp", fmt.Sprintf("%s:%s", s.IP, s.Port), config)
 	return
 }
 
@@ -8,18 +8,12 @@ import (
 )
 
 type terminal struct {
-	client *ssh.Client
+	newSession func() (*ssh.Session, error)
 }
 
 // Terminal opens a new Terminal against an SSL connection
 func (s *SSH) Terminal() (err error) {
-	client, err := s.connect()
-	if err != nil {
-		return
-	}
-	defer client.Close()
-
-	return (&terminal{client: client}).Terminal()
+	return (&terminal{newSession: s.conn.newSession}).Terminal()
 }
 
 func (t *terminal) Terminal() (err error) {
@@ -27,7 +21,7 @@ func (t *terminal) Terminal() (err error) {
 		termWidth, termHeight int
 	)
 
-	session, err := t.client.NewSession()
+	session, err := t.newSession()
 	if err != nil {
 		return
 	}
@@ -1,14 +1,13 @@
 package http
 
 import (
+	"denver/pkg/util"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
-
-	"github.com/cheggaaa/pb/v3"
 )
 
 // HTTP storage implementation
@@ -47,8 +46,7 @@ func (h *HTTP) Download(origin, destination string) (destFile string, err error)
 	}
 	defer f.Close()
 
-	tmpl := `{{ green "Progress:" }} {{counters . | blue}} {{ bar . "[" ("#" | green) ("#" | blue) ("."|white) "]" }} {{percent . | white}} {{speed . }}`
-	bar := pb.ProgressBarTemplate(tmpl).Start64(size)
+	bar := util.StartProgress(size)
 	barReader := bar.NewProxyReader(resp.Body)
 	_, err = io.Copy(f, barReader)
 	bar.Finish()
@@ -0,0 +1,27 @@
+package updater
+
+import "log"
+
+// DryRunUpdater only checks for updates while dryRun is set, they are installed otherwise
+type DryRunUpdater struct {
+	Updater
+	dryRun *bool
+}
+
+// NewDryRunUpdater returns a pointer to DryRunUpdater
+func NewDryRunUpdater(updater Updater, dryRun *bool) *DryRunUpdater {
+	return &DryRunUpdater{
+		Updater: updater,
+		dryRun:  dryRun,
+	}
+}
+
+// Update installs the update unless dryRun is set
+func (u *DryRunUpdater) Update() error {
+	if *u.dryRun {
+		log.Println("Dry-run, denver is not updated")
+		return nil
+	}
+
+	return u.Updater.Update()
+}
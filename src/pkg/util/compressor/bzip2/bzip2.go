@@ -2,13 +2,13 @@ package bzip2
 
 import (
 	"bufio"
+	"denver/pkg/util"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/cheggaaa/pb/v3"
-
 	"github.com/dsnet/compress/bzip2"
 )
 
@@ -41,8 +41,7 @@ func (b *Bzip2) Decompress(origin, destination string, filesize int) error {
 	}
 	defer out.Close()
 
-	tmpl := `{{ green "Progress:" }} {{counters . | blue}} {{ bar . "[" ("#" | green) ("#" | blue) ("."|white) "]" }} {{percent . | white}} {{speed . }}`
-	bar := pb.ProgressBarTemplate(tmpl).Start(filesize)
+	bar := util.StartProgress(int64(filesize))
 	barReader := bar.NewProxyReader(r)
 	if _, err := io.Copy(out, barReader); err != nil {
 		return err
@@ -51,3 +50,41 @@ func (b *Bzip2) Decompress(origin, destination string, filesize int) error {
 
 	return nil
 }
+
+// Compress a single file with bzip2
+func (b *Bzip2) Compress(origins []string, destination string) error {
+	if len(origins) != 1 {
+		return fmt.Errorf("bzip2 compresses exactly one file, %d given", len(origins))
+	}
+
+	f, err := os.Open(origins[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w, err := bzip2.NewWriter(out, nil)
+	if err != nil {
+		return err
+	}
+
+	bar := util.StartProgress(info.Size())
+	barReader := bar.NewProxyReader(bufio.NewReader(f))
+	if _, err := io.Copy(w, barReader); err != nil {
+		return err
+	}
+	bar.Finish()
+
+	return w.Close()
+}
@@ -4,6 +4,8 @@ import (
 	"denver/pkg/util/compressor/bzip2"
 	"denver/pkg/util/compressor/tar"
 	"denver/pkg/util/compressor/zip"
+	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -13,6 +15,11 @@ type Compressor interface {
 	Decompress(origin, destination string, filesize int) error
 }
 
+// Archiver represents an engine able to compress files
+type Archiver interface {
+	Compress(origins []string, destination string) error
+}
+
 // MultiCompressor supports multiple compressions
 type MultiCompressor struct {
 	compressors map[string]Compressor
@@ -27,7 +34,7 @@ func NewMultiCompressor() *MultiCompressor {
 	}}
 }
 
-// Decompress a file in origin to destination
+// Decompress a file in origin to destination, intermediate files are extracted in destination
 func (m *MultiCompressor) Decompress(origin, destination string, filesize int) error {
 	for {
 		ext := filepath.Ext(origin)
@@ -39,6 +46,42 @@ func (m *MultiCompressor) Decompress(origin, destination string, filesize int) e
 		if error != nil {
 			return error
 		}
-		origin = strings.TrimSuffix(origin, ext)
+		origin = filepath.Join(destination, filepath.Base(strings.TrimSuffix(origin, ext)))
+	}
+}
+
+// Compress origins to destination, one engine being applied per extension from
+// the innermost one, e.g. tar then bzip2 for a .tar.bz2 file
+func (m *MultiCompressor) Compress(origins []string, destination string) (err error) {
+	var exts []string
+	name := destination
+	for {
+		ext := filepath.Ext(name)
+		if _, ok := m.compressors[ext].(Archiver); !ok {
+			break
+		}
+		exts = append([]string{ext}, exts...)
+		name = strings.TrimSuffix(name, ext)
 	}
+
+	if len(exts) == 0 {
+		return fmt.Errorf("unsupported archive format %s", filepath.Base(destination))
+	}
+
+	for i, ext := range exts {
+		target := name + strings.Join(exts[:i+1], "")
+		if err = m.compressors[ext].(Archiver).Compress(origins, target); err != nil {
+			return
+		}
+
+		// Intermediate files are not needed anymore
+		if i > 0 {
+			if err = os.Remove(origins[0]); err != nil {
+				return
+			}
+		}
+		origins = []string{target}
+	}
+
+	return
 }
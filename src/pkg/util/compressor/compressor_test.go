@@ -0,0 +1,52 @@
+package compressor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressedFilesCanBeDecompressed(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "compressor")
+	assert.NoError(err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	origins := []string{
+		filepath.Join(dir, "userdata.vdi"),
+		filepath.Join(dir, "export.json"),
+	}
+	for _, origin := range origins {
+		assert.NoError(ioutil.WriteFile(origin, []byte(filepath.Base(origin)), 0644))
+	}
+
+	c := NewMultiCompressor()
+	archive := filepath.Join(dir, "archive.tar.bz2")
+	assert.NoError(c.Compress(origins, archive))
+
+	_, err = os.Stat(filepath.Join(dir, "archive.tar"))
+	assert.True(os.IsNotExist(err), "intermediate file has been removed")
+
+	destination := filepath.Join(dir, "out")
+	assert.NoError(os.MkdirAll(destination, os.ModePerm))
+	assert.NoError(c.Decompress(archive, destination, 0))
+
+	for _, origin := range origins {
+		content, err := ioutil.ReadFile(filepath.Join(destination, filepath.Base(origin)))
+		assert.NoError(err)
+		assert.Equal(filepath.Base(origin), string(content))
+	}
+}
+
+func TestCompressFailsWithUnknownFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	err := NewMultiCompressor().Compress([]string{"userdata.vdi"}, "archive.rar")
+	assert.EqualError(err, "unsupported archive format archive.rar")
+}
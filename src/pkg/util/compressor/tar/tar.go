@@ -1,6 +1,13 @@
 package tar
 
 import (
+	"archive/tar"
+	"denver/pkg/util"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cheggaaa/pb/v3"
 	"github.com/mholt/archiver"
 )
 
@@ -12,3 +19,60 @@ type Tar struct {
 func (b *Tar) Decompress(origin, destination string, filesize int) error {
 	return archiver.Unarchive(origin, destination)
 }
+
+// Compress files at the root of a tar archive
+func (b *Tar) Compress(origins []string, destination string) error {
+	var total int64
+	for _, origin := range origins {
+		info, err := os.Stat(origin)
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+	}
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := tar.NewWriter(out)
+
+	bar := util.StartProgress(total)
+	defer bar.Finish()
+
+	for _, origin := range origins {
+		if err := b.add(w, bar, origin); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+func (b *Tar) add(w *tar.Writer, bar *pb.ProgressBar, origin string) error {
+	f, err := os.Open(origin)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.Base(origin)
+
+	if err = w.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, bar.NewProxyReader(f))
+	return err
+}
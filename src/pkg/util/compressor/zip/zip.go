@@ -2,13 +2,12 @@ package zip
 
 import (
 	"archive/zip"
+	"denver/pkg/util"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
-
-	"github.com/cheggaaa/pb/v3"
 )
 
 // Zip implements zip compressor
@@ -59,8 +58,7 @@ func (z *Zip) Decompress(origin, destination string, filesize int) error {
 			return err
 		}
 
-		tmpl := `{{ green "Progress:" }} {{counters . | blue}} {{ bar . "[" ("#" | green) ("#" | blue) ("."|white) "]" }} {{percent . | white}} {{speed . }}`
-		bar := pb.ProgressBarTemplate(tmpl).Start(filesize)
+		bar := util.StartProgress(int64(filesize))
 		barReader := bar.NewProxyReader(rc)
 
 		_, err = io.Copy(outFile, barReader)
@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// DryRunExecutor prints the commands instead of running them. The read-only ones are still
+// run so that the preview follows the actual state of the host
+type DryRunExecutor struct {
+	mutex    sync.Mutex
+	executor Executor
+	readOnly func(args []string) bool
+	printer  *log.Logger
+	commands [][]string
+}
+
+// NewDryRunExecutor returns a pointer to DryRunExecutor, readOnly tells which commands can be run
+func NewDryRunExecutor(executor Executor, readOnly func(args []string) bool, printer *log.Logger) *DryRunExecutor {
+	return &DryRunExecutor{
+		executor: executor,
+		readOnly: readOnly,
+		printer:  printer,
+	}
+}
+
+// Execute runs a read-only command, any other command is recorded and printed
+func (e *DryRunExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, Options{})
+}
+
+// ExecuteWithOptions runs a read-only command, any other command is recorded and printed
+func (e *DryRunExecutor) ExecuteWithOptions(args []string, options Options) (string, error) {
+	if e.readOnly(args) {
+		return e.executor.ExecuteWithOptions(args, options)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.commands = append(e.commands, args)
+	e.printer.Println(fmt.Sprintf("%s %s",
+		aurora.Bold(aurora.Yellow("[DRY-RUN]")),
+		Quote(args),
+	))
+
+	return "", nil
+}
+
+// Commands returns the commands which have not been run, in order
+func (e *DryRunExecutor) Commands() [][]string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.commands
+}
+
+// Quote joins the arguments of a command, quoting the ones a shell would split
+func Quote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'\\$`") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// ShellQuote makes value a single argument of a POSIX shell, the one of the guest for instance
+func ShellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeExecutor struct {
+	executed [][]string
+}
+
+func (f *fakeExecutor) Execute(args []string) (string, error) {
+	return f.ExecuteWithOptions(args, Options{})
+}
+
+func (f *fakeExecutor) ExecuteWithOptions(args []string, options Options) (string, error) {
+	f.executed = append(f.executed, args)
+	return "output", nil
+}
+
+func isList(args []string) bool {
+	return len(args) > 1 && args[1] == "list"
+}
+
+func TestDryRunOnlyRunsReadOnlyCommands(t *testing.T) {
+	assert := assert.New(t)
+	fake := &fakeExecutor{}
+	var out bytes.Buffer
+
+	e := NewDryRunExecutor(fake, isList, log.New(&out, "", 0))
+
+	output, err := e.Execute([]string{"VBoxManage", "list", "vms"})
+	assert.NoError(err)
+	assert.Equal("output", output)
+
+	output, err = e.Execute([]string{"VBoxManage", "unregistervm", "denver", "--delete"})
+	assert.NoError(err)
+	assert.Equal("", output)
+
+	assert.Equal([][]string{{"VBoxManage", "list", "vms"}}, fake.executed)
+	assert.Equal([][]string{{"VBoxManage", "unregistervm", "denver", "--delete"}}, e.Commands())
+	assert.True(strings.HasSuffix(out.String(), "VBoxManage unregistervm denver --delete\n"))
+}
+
+func TestQuote(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(
+		`VBoxManage snapshot denver take "before migration" --description ""`,
+		Quote([]string{"VBoxManage", "snapshot", "denver", "take", "before migration", "--description", ""}),
+	)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`'nginx'`, ShellQuote("nginx"))
+	assert.Equal(`'echo '\''$HOME'\'''`, ShellQuote("echo '$HOME'"))
+}
@@ -2,39 +2,164 @@ package executor
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"os/exec"
-	"sync"
+	"strings"
+	"time"
 )
 
-// Executor with mutex
-type Executor struct {
-	mutex sync.Mutex
+// Executor runs commands on the host
+type Executor interface {
+	Execute(args []string) (string, error)
+	ExecuteWithOptions(args []string, options Options) (string, error)
 }
 
-// NewExecutor returns a pointer to Executor
-func NewExecutor() *Executor {
-	return &Executor{
-		mutex: sync.Mutex{},
+// Options of a single command
+type Options struct {
+	// Timeout kills the command when it is reached, there is none when zero
+	Timeout time.Duration
+	// Interruptible commands are killed on cancellation and get the Ctrl-C of the terminal,
+	// which sudo needs to prompt for a password. The other ones are waited for so that the
+	// VM is not left half changed
+	Interruptible bool
+	// Stream logs the output line by line while the command runs
+	Stream bool
+	// Cleanup commands undo what an interrupted operation has done, they are still started
+	// once ctx is canceled
+	Cleanup bool
+	// Background commands are run by the probe aside from the command being run, they are not recorded
+	Background bool
+}
+
+// Error is returned when a command fails, ExitCode is -1 when it did not exit by itself
+type Error struct {
+	Args     []string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Stderr == "" && e.Stdout == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s\n%s\n%s", e.Err.Error(), e.Stderr, e.Stdout)
+}
+
+// IsCanceled tells whether a command failed because the context has been canceled
+func IsCanceled(err error) bool {
+	if e, ok := err.(*Error); ok {
+		return e.Err == context.Canceled
+	}
+
+	return false
+}
+
+// DefaultExecutor runs the commands until ctx is canceled
+type DefaultExecutor struct {
+	ctx context.Context
+}
+
+// NewDefaultExecutor returns a pointer to DefaultExecutor
+func NewDefaultExecutor(ctx context.Context) *DefaultExecutor {
+	return &DefaultExecutor{
+		ctx: ctx,
 	}
 }
 
 // Execute executes a command with arguments
-func (e *Executor) Execute(args []string) (string, error) {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
+func (e *DefaultExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, Options{})
+}
 
-	cmd := exec.Command(args[0], args[1:]...)
+// ExecuteWithOptions executes a command with arguments, no command but the cleanup ones is started
+// once ctx is canceled
+func (e *DefaultExecutor) ExecuteWithOptions(args []string, options Options) (string, error) {
+	if err := e.ctx.Err(); err != nil && !options.Cleanup {
+		return "", &Error{Args: args, ExitCode: -1, Err: err}
+	}
 
-	var stdOut bytes.Buffer
-	cmd.Stdout = &stdOut
+	// Only the timeout and the cancellation of interruptible commands kill the process
+	ctx := context.Background()
+	if options.Interruptible {
+		ctx = e.ctx
+	}
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if !options.Interruptible {
+		cmd.SysProcAttr = sysProcAttr()
+	}
 
-	var stdErr bytes.Buffer
-	cmd.Stderr = &stdErr
+	var stdOut, stdErr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdOut, &stdErr
+	if options.Stream {
+		stdOutLog, stdErrLog := newLogWriter(), newLogWriter()
+		defer stdOutLog.Flush()
+		defer stdErrLog.Flush()
+		cmd.Stdout = io.MultiWriter(&stdOut, stdOutLog)
+		cmd.Stderr = io.MultiWriter(&stdErr, stdErrLog)
+	}
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf(fmt.Sprintf("%s\n%s\n%s", err.Error(), stdErr.String(), stdOut.String()))
+		execErr := &Error{
+			Args:     args,
+			ExitCode: -1,
+			Stdout:   stdOut.String(),
+			Stderr:   stdErr.String(),
+			Err:      err,
+		}
+		if ctx.Err() != nil {
+			execErr.Err = ctx.Err()
+		} else if exitErr, ok := err.(*exec.ExitError); ok && exitErr.Exited() {
+			execErr.ExitCode = exitErr.ExitCode()
+		}
+		return "", execErr
 	}
 
 	return stdOut.String(), nil
 }
+
+// logWriter logs each complete line written to it
+type logWriter struct {
+	line bytes.Buffer
+}
+
+func newLogWriter() *logWriter {
+	return &logWriter{}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.line.Write(p)
+	for {
+		line, err := w.line.ReadString('\n')
+		if err != nil {
+			// Incomplete, it is kept for the next write
+			w.line.Reset()
+			w.line.WriteString(line)
+			return len(p), nil
+		}
+		w.log(line)
+	}
+}
+
+// Flush logs what is left without a trailing newline
+func (w *logWriter) Flush() {
+	w.log(w.line.String())
+	w.line.Reset()
+}
+
+func (w *logWriter) log(line string) {
+	if line = strings.TrimSpace(line); line != "" {
+		log.Println(line)
+	}
+}
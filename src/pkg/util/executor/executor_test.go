@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func skipOnWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands are run through sh")
+	}
+}
+
+func TestErrorCarriesExitCodeAndStderr(t *testing.T) {
+	skipOnWindows(t)
+	assert := assert.New(t)
+
+	_, err := NewDefaultExecutor(context.Background()).Execute([]string{"sh", "-c", "echo oops >&2; exit 3"})
+
+	execErr, ok := err.(*Error)
+	if assert.True(ok) {
+		assert.Equal(3, execErr.ExitCode)
+		assert.Equal("oops\n", execErr.Stderr)
+	}
+}
+
+func TestTimeoutKillsTheCommand(t *testing.T) {
+	skipOnWindows(t)
+	assert := assert.New(t)
+
+	_, err := NewDefaultExecutor(context.Background()).ExecuteWithOptions([]string{"sleep", "5"}, Options{Timeout: 50 * time.Millisecond})
+
+	execErr, ok := err.(*Error)
+	if assert.True(ok) {
+		assert.Equal(-1, execErr.ExitCode)
+		assert.Equal(context.DeadlineExceeded, execErr.Err)
+	}
+}
+
+func TestNothingIsStartedOnceCanceled(t *testing.T) {
+	skipOnWindows(t)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewDefaultExecutor(ctx).Execute([]string{"true"})
+	assert.True(IsCanceled(err))
+}
+
+func TestCleanupIsStartedOnceCanceled(t *testing.T) {
+	skipOnWindows(t)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out, err := NewDefaultExecutor(ctx).ExecuteWithOptions([]string{"echo", "undone"}, Options{Cleanup: true})
+	assert.NoError(err)
+	assert.Equal("undone\n", out)
+}
+
+func TestCancellationWaitsForTheRunningCommand(t *testing.T) {
+	skipOnWindows(t)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	out, err := NewDefaultExecutor(ctx).Execute([]string{"sh", "-c", "sleep 0.2; echo done"})
+	assert.NoError(err)
+	assert.Equal("done\n", out)
+}
+
+func TestCancellationKillsInterruptibleCommands(t *testing.T) {
+	skipOnWindows(t)
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := NewDefaultExecutor(ctx).ExecuteWithOptions([]string{"sleep", "5"}, Options{Interruptible: true})
+	assert.True(IsCanceled(err))
+}
+
+func TestStreamLogsEachLine(t *testing.T) {
+	skipOnWindows(t)
+	assert := assert.New(t)
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	out, err := NewDefaultExecutor(context.Background()).ExecuteWithOptions(
+		[]string{"sh", "-c", "echo 0%...; echo failed >&2; printf 100%%"},
+		Options{Stream: true},
+	)
+	assert.NoError(err)
+	assert.Equal("0%...\n100%", out)
+	assert.Contains(logs.String(), "0%...\n")
+	assert.Contains(logs.String(), "failed\n")
+	assert.Contains(logs.String(), "100%\n")
+}
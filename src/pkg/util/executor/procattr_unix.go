@@ -0,0 +1,10 @@
+// +build !windows
+
+package executor
+
+import "syscall"
+
+// A new process group keeps the command out of the reach of the Ctrl-C of the terminal
+func sysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
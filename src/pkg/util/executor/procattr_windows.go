@@ -0,0 +1,8 @@
+package executor
+
+import "syscall"
+
+// A new process group keeps the command out of the reach of the Ctrl-C of the console
+func sysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
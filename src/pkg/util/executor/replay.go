@@ -0,0 +1,205 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Interaction is a command and what it returned, as stored in fixture files.
+// Values of the variables appear as ${name} so that fixtures do not depend on the host
+type Interaction struct {
+	Args     []string `json:"args"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+	ExitCode int      `json:"exitCode,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// RecordingExecutor runs the commands and records them with their outputs
+type RecordingExecutor struct {
+	mutex        sync.Mutex
+	executor     Executor
+	variables    map[string]string
+	interactions []Interaction
+}
+
+// NewRecordingExecutor returns a pointer to RecordingExecutor
+func NewRecordingExecutor(executor Executor, variables map[string]string) *RecordingExecutor {
+	return &RecordingExecutor{
+		executor:  executor,
+		variables: variables,
+	}
+}
+
+// Execute runs and records a command
+func (e *RecordingExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, Options{})
+}
+
+// ExecuteWithOptions runs and records a command, the background ones are only run
+func (e *RecordingExecutor) ExecuteWithOptions(args []string, options Options) (string, error) {
+	out, err := e.executor.ExecuteWithOptions(args, options)
+	if options.Background {
+		return out, err
+	}
+
+	interaction := Interaction{Args: args, Stdout: out}
+	if err != nil {
+		interaction.ExitCode = -1
+		interaction.Error = err.Error()
+		if execErr, ok := err.(*Error); ok {
+			interaction.Stdout = execErr.Stdout
+			interaction.Stderr = execErr.Stderr
+			interaction.ExitCode = execErr.ExitCode
+			interaction.Error = execErr.Err.Error()
+		}
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.interactions = append(e.interactions, substitute(interaction, e.variables, false))
+
+	return out, err
+}
+
+// Save writes the recorded interactions to a fixture file
+func (e *RecordingExecutor) Save(path string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	body, err := json.MarshalIndent(e.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, body, 0644)
+}
+
+// BackgroundExecutor runs every command as a background one
+type BackgroundExecutor struct {
+	executor Executor
+}
+
+// NewBackgroundExecutor returns a pointer to BackgroundExecutor
+func NewBackgroundExecutor(executor Executor) *BackgroundExecutor {
+	return &BackgroundExecutor{
+		executor: executor,
+	}
+}
+
+// Execute runs a command as a background one
+func (e *BackgroundExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, Options{})
+}
+
+// ExecuteWithOptions runs a command as a background one
+func (e *BackgroundExecutor) ExecuteWithOptions(args []string, options Options) (string, error) {
+	options.Background = true
+	return e.executor.ExecuteWithOptions(args, options)
+}
+
+// ReplayExecutor serves the interactions of a fixture file in order, any other command fails
+type ReplayExecutor struct {
+	mutex        sync.Mutex
+	path         string
+	interactions []Interaction
+}
+
+// LoadReplayExecutor returns a pointer to ReplayExecutor serving the interactions of path
+func LoadReplayExecutor(path string, variables map[string]string) (*ReplayExecutor, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var interactions []Interaction
+	if err = json.Unmarshal(body, &interactions); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %s", path, err)
+	}
+	for i := range interactions {
+		interactions[i] = substitute(interactions[i], variables, true)
+	}
+
+	return &ReplayExecutor{
+		path:         path,
+		interactions: interactions,
+	}, nil
+}
+
+// Execute replays the next interaction if it is the command expected
+func (e *ReplayExecutor) Execute(args []string) (string, error) {
+	return e.ExecuteWithOptions(args, Options{})
+}
+
+// ExecuteWithOptions replays the next interaction if it is the command expected
+func (e *ReplayExecutor) ExecuteWithOptions(args []string, options Options) (string, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.interactions) == 0 {
+		return "", fmt.Errorf("unexpected command %s, %s has been fully replayed", Quote(args), e.path)
+	}
+
+	interaction := e.interactions[0]
+	if Quote(interaction.Args) != Quote(args) {
+		return "", fmt.Errorf("unexpected command %s, %s expects %s", Quote(args), e.path, Quote(interaction.Args))
+	}
+	e.interactions = e.interactions[1:]
+
+	if interaction.Error == "" && interaction.ExitCode == 0 {
+		return interaction.Stdout, nil
+	}
+
+	return "", &Error{
+		Args:     args,
+		ExitCode: interaction.ExitCode,
+		Stdout:   interaction.Stdout,
+		Stderr:   interaction.Stderr,
+		Err:      errors.New(interaction.Error),
+	}
+}
+
+// Remaining returns the interactions which have not been replayed
+func (e *ReplayExecutor) Remaining() []Interaction {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.interactions
+}
+
+// substitute replaces the variables by their values when expand is set, the values by the
+// variables otherwise. Longer values go first, a path may hold another one
+func substitute(interaction Interaction, variables map[string]string, expand bool) Interaction {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(variables[names[i]]) > len(variables[names[j]])
+	})
+
+	var pairs []string
+	for _, name := range names {
+		if expand {
+			pairs = append(pairs, fmt.Sprintf("${%s}", name), variables[name])
+		} else if variables[name] != "" {
+			pairs = append(pairs, variables[name], fmt.Sprintf("${%s}", name))
+		}
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	args := make([]string, len(interaction.Args))
+	for i, arg := range interaction.Args {
+		args[i] = replacer.Replace(arg)
+	}
+	interaction.Args = args
+	interaction.Stdout = replacer.Replace(interaction.Stdout)
+	interaction.Stderr = replacer.Replace(interaction.Stderr)
+
+	return interaction
+}
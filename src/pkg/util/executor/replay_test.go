@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingExecutor struct{}
+
+func (f failingExecutor) Execute(args []string) (string, error) {
+	return f.ExecuteWithOptions(args, Options{})
+}
+
+func (f failingExecutor) ExecuteWithOptions(args []string, options Options) (string, error) {
+	if args[1] == "showvminfo" {
+		return "", &Error{Args: args, ExitCode: 1, Stderr: "Could not find a registered machine named 'denver'\n", Err: errors.New("exit status 1")}
+	}
+	return "/home/jdoe/denver/store/userdata.vdi\n", nil
+}
+
+func TestRecordedInteractionsAreReplayed(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	fixture := filepath.Join(dir, "fixture.json")
+
+	recorder := NewRecordingExecutor(failingExecutor{}, map[string]string{"dir": "/home/jdoe/denver"})
+	_, _ = recorder.Execute([]string{"VBoxManage", "list", "hdds"})
+	_, _ = recorder.Execute([]string{"VBoxManage", "showvminfo", "denver"})
+	assert.NoError(recorder.Save(fixture))
+
+	body, err := ioutil.ReadFile(fixture)
+	assert.NoError(err)
+	assert.Contains(string(body), `"stdout": "${dir}/store/userdata.vdi\n"`)
+
+	replay, err := LoadReplayExecutor(fixture, map[string]string{"dir": "/tmp/denver"})
+	assert.NoError(err)
+
+	out, err := replay.Execute([]string{"VBoxManage", "list", "hdds"})
+	assert.NoError(err)
+	assert.Equal("/tmp/denver/store/userdata.vdi\n", out)
+
+	_, err = replay.Execute([]string{"VBoxManage", "showvminfo", "denver"})
+	execErr, ok := err.(*Error)
+	if assert.True(ok) {
+		assert.Equal(1, execErr.ExitCode)
+		assert.Equal("Could not find a registered machine named 'denver'\n", execErr.Stderr)
+	}
+	assert.Empty(replay.Remaining())
+}
+
+func TestBackgroundCommandsAreNotRecorded(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	fixture := filepath.Join(dir, "fixture.json")
+
+	recorder := NewRecordingExecutor(failingExecutor{}, nil)
+	out, err := NewBackgroundExecutor(recorder).Execute([]string{"VBoxManage", "list", "runningvms"})
+	assert.NoError(err)
+	assert.Equal("/home/jdoe/denver/store/userdata.vdi\n", out)
+	_, _ = recorder.Execute([]string{"VBoxManage", "list", "hdds"})
+	assert.NoError(recorder.Save(fixture))
+
+	replay, err := LoadReplayExecutor(fixture, nil)
+	assert.NoError(err)
+	assert.Equal([]Interaction{{
+		Args:   []string{"VBoxManage", "list", "hdds"},
+		Stdout: "/home/jdoe/denver/store/userdata.vdi\n",
+	}}, replay.Remaining())
+}
+
+func TestReplayFailsOnUnexpectedCommands(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	fixture := filepath.Join(dir, "fixture.json")
+	assert.NoError(ioutil.WriteFile(fixture, []byte(`[{"args": ["VBoxManage", "list", "vms"]}]`), 0644))
+
+	replay, err := LoadReplayExecutor(fixture, nil)
+	assert.NoError(err)
+
+	_, err = replay.Execute([]string{"VBoxManage", "startvm", "denver"})
+	assert.EqualError(err, "unexpected command VBoxManage startvm denver, "+fixture+" expects VBoxManage list vms")
+
+	_, err = replay.Execute([]string{"VBoxManage", "list", "vms"})
+	assert.NoError(err)
+
+	_, err = replay.Execute([]string{"VBoxManage", "list", "vms"})
+	assert.EqualError(err, "unexpected command VBoxManage list vms, "+fixture+" has been fully replayed")
+}
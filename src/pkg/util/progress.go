@@ -0,0 +1,26 @@
+package util
+
+import (
+	"io/ioutil"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+const progressTemplate = `{{ green "Progress:" }} {{counters . | blue}} {{ bar . "[" ("#" | green) ("#" | blue) ("."|white) "]" }} {{percent . | white}} {{speed . }}`
+
+var progressDisabled bool
+
+// DisableProgress hides the progress bars, for the outputs read by scripts
+func DisableProgress() {
+	progressDisabled = true
+}
+
+// StartProgress starts a progress bar up to total
+func StartProgress(total int64) *pb.ProgressBar {
+	bar := pb.New64(total).SetTemplate(pb.ProgressBarTemplate(progressTemplate))
+	if progressDisabled {
+		bar.SetWriter(ioutil.Discard)
+	}
+
+	return bar.Start()
+}
@@ -4,7 +4,6 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -91,11 +90,10 @@ func FileChecksum(path string) (string, error) {
 	}
 	defer f.Close()
 
-	c, err := ioutil.ReadAll(f)
-	if err != nil {
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
 		return "", err
 	}
 
-	checksum := sha256.Sum256(c)
-	return hex.EncodeToString(checksum[:]), nil
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
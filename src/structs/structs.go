@@ -1,12 +1,69 @@
 package structs
 
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// instanceNameRegexp matches the names usable as a directory of the store and as a VM name
+var instanceNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// channels are the directories of the legacy instance in store/, holding its RBI
+var channels = []string{"stable", "beta"}
+
 // InstanceConf : TODO
 type InstanceConf struct {
-	Name     string
-	Provider string
-	Vmem     int
-	Vcpu     int
-	Localip  string
+	Name         string
+	Provider     string
+	Vmem         int
+	Vcpu         int
+	Localip      string
+	Userdata     string
+	Userdatasize int
+	Sshuser      string
+	Sshport      int
+	Ports        []string
+	Mountpoint   string
+	Automount    bool
+	Healthchecks []HealthCheck
+	Hooks        Hooks
+	// Store is the instance directory, relative to the working directory
+	Store string `mapstructure:"-"`
+	// ConfigKey is the path of the instance section in the configuration file
+	ConfigKey string `mapstructure:"-"`
+}
+
+// HealthCheck is a named check of a system of the guest, exactly one of Unit, Port, HTTP and Command is set:
+// the systemd unit is active, a TCP port is listening, the URL answers with a 2xx status or the command succeeds
+type HealthCheck struct {
+	Name    string
+	Unit    string
+	Port    int
+	HTTP    string
+	Command string
+}
+
+// Hooks are the commands run along the lifecycle of the instance, in the order they are declared
+type Hooks struct {
+	PreStart   []Hook `mapstructure:"pre-start"`
+	PostStart  []Hook `mapstructure:"post-start"`
+	PreStop    []Hook `mapstructure:"pre-stop"`
+	PostStop   []Hook `mapstructure:"post-stop"`
+	PreUpdate  []Hook `mapstructure:"pre-update"`
+	PostUpdate []Hook `mapstructure:"post-update"`
+}
+
+// Hook runs exactly one of Host, a command run on the computer, and Guest, a command run in the instance
+// through SSH. Timeout is in seconds, a failing hook stops the operation unless ContinueOnError is set
+type Hook struct {
+	Name            string
+	Host            string
+	Guest           string
+	Timeout         int
+	ContinueOnError bool `mapstructure:"continue-on-error"`
 }
 
 // UserConf : TODO
@@ -29,8 +86,13 @@ type Provider struct {
 
 // Config : TODO
 type Config struct {
-	Channel string
-	RBIURL  string
+	Channel         string
+	RBIURL          string
+	Defaultinstance string
+	// Stoptimeout is the number of seconds given to the guest to shut down before it is powered off
+	Stoptimeout int
+	// Starttimeout is the number of seconds start waits for the guest to be ready
+	Starttimeout int
 }
 
 // Denver : TODO
@@ -38,6 +100,7 @@ type Denver struct {
 	Version   string
 	Config    *Config
 	Instance  *InstanceConf
+	Instances map[string]*InstanceConf
 	UserInfo  *UserConf
 	Providers map[string]Provider
 }
@@ -48,7 +111,133 @@ func NewDenverConfig() *Denver {
 		Version:   "",
 		Config:    &Config{},
 		Instance:  &InstanceConf{},
+		Instances: nil,
 		UserInfo:  &UserConf{},
 		Providers: nil,
 	}
 }
+
+// GetInstances returns every configured instance indexed by name, the legacy
+// `instance` section keeps its own name and its files directly under store/
+func (d *Denver) GetInstances() (map[string]*InstanceConf, error) {
+	instances := map[string]*InstanceConf{}
+
+	if d.Instance != nil && d.Instance.Name != "" {
+		instance := *d.Instance
+		instance.Store = "store"
+		instance.ConfigKey = "instance"
+		instances[instance.Name] = d.withDefaults(&instance)
+	}
+
+	for key, conf := range d.Instances {
+		if conf == nil {
+			conf = &InstanceConf{}
+		}
+		instance := *conf
+		if instance.Name == "" {
+			instance.Name = key
+		}
+		if err := d.checkInstanceName(instance.Name); err != nil {
+			return nil, err
+		}
+		if _, ok := instances[instance.Name]; ok {
+			return nil, fmt.Errorf("instance %s is declared twice", instance.Name)
+		}
+		instance.Store = filepath.Join("store", instance.Name)
+		instance.ConfigKey = fmt.Sprintf("instances.%s", key)
+		instances[instance.Name] = d.withDefaults(&instance)
+	}
+
+	return instances, nil
+}
+
+// GetInstance returns the named instance, or the default one when name is empty
+func (d *Denver) GetInstance(name string) (*InstanceConf, error) {
+	instances, err := d.GetInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name, err = d.defaultInstanceName(instances)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	instance, ok := instances[name]
+	if !ok {
+		return nil, fmt.Errorf("instance %s not found", name)
+	}
+
+	return instance, nil
+}
+
+// InstanceNames returns the sorted names of every configured instance
+func InstanceNames(instances map[string]*InstanceConf) []string {
+	var names []string
+	for name := range instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (d *Denver) defaultInstanceName(instances map[string]*InstanceConf) (string, error) {
+	if d.Config != nil && d.Config.Defaultinstance != "" {
+		return d.Config.Defaultinstance, nil
+	}
+
+	if d.Instance != nil && d.Instance.Name != "" {
+		return d.Instance.Name, nil
+	}
+
+	names := InstanceNames(instances)
+	switch len(names) {
+	case 0:
+		return "", fmt.Errorf("no instance configured")
+	case 1:
+		return names[0], nil
+	}
+
+	return "", fmt.Errorf(
+		"several instances are configured (%s), select one with --instance or config.defaultinstance",
+		strings.Join(names, ", "),
+	)
+}
+
+// checkInstanceName refuses the names which can't be a directory of store/ of their own, the
+// legacy instance keeps its files directly under store/ and its RBI in store/<channel>
+func (d *Denver) checkInstanceName(name string) error {
+	if !instanceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q, only letters, digits, - and _ are allowed", name)
+	}
+
+	reserved := channels
+	if d.Config != nil && d.Config.Channel != "" {
+		reserved = append([]string{d.Config.Channel}, channels...)
+	}
+	for _, channel := range reserved {
+		// The store may be on a case insensitive filesystem
+		if strings.EqualFold(name, channel) {
+			return fmt.Errorf("invalid instance name %s, store/%s holds the RBI of the %s channel", name, channel, channel)
+		}
+	}
+
+	return nil
+}
+
+func (d *Denver) withDefaults(instance *InstanceConf) *InstanceConf {
+	if instance.Userdatasize == 0 && d.UserInfo != nil {
+		instance.Userdatasize = d.UserInfo.Userdatasize
+	}
+	if instance.Sshuser == "" {
+		instance.Sshuser = "ldevuser"
+	}
+	if instance.Sshport == 0 {
+		instance.Sshport = 22
+	}
+
+	return instance
+}
@@ -0,0 +1,65 @@
+package structs
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getConfig(names ...string) *Denver {
+	config := NewDenverConfig()
+	config.Config.Channel = "stable"
+	config.Instance.Name = "denver"
+	config.Instances = map[string]*InstanceConf{}
+	for _, name := range names {
+		config.Instances[name] = &InstanceConf{}
+	}
+
+	return config
+}
+
+func TestInstancesHaveTheirOwnStore(t *testing.T) {
+	assert := assert.New(t)
+
+	instances, err := getConfig("legacy-php", "php_7").GetInstances()
+	assert.NoError(err)
+	assert.Equal("store", instances["denver"].Store)
+	assert.Equal("store/legacy-php", filepath.ToSlash(instances["legacy-php"].Store))
+	assert.Equal("store/php_7", filepath.ToSlash(instances["php_7"].Store))
+}
+
+func TestInstanceNamesLeavingTheStoreAreRefused(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range []string{"../x", "a/b", `a\b`, "..", "php 7", "é"} {
+		_, err := getConfig(name).GetInstances()
+		assert.EqualError(err, fmt.Sprintf("invalid instance name %q, only letters, digits, - and _ are allowed", name))
+	}
+}
+
+func TestEmptyInstanceNameIsRefused(t *testing.T) {
+	assert := assert.New(t)
+	config := getConfig()
+	config.Instances["legacy"] = &InstanceConf{}
+	config.Instances[""] = &InstanceConf{}
+
+	_, err := config.GetInstances()
+	assert.EqualError(err, `invalid instance name "", only letters, digits, - and _ are allowed`)
+}
+
+func TestInstanceNamesOfTheLegacyStoreAreRefused(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := getConfig("stable").GetInstances()
+	assert.EqualError(err, "invalid instance name stable, store/stable holds the RBI of the stable channel")
+
+	_, err = getConfig("Beta").GetInstances()
+	assert.EqualError(err, "invalid instance name Beta, store/beta holds the RBI of the beta channel")
+
+	config := getConfig("nightly")
+	config.Config.Channel = "nightly"
+	_, err = config.GetInstances()
+	assert.EqualError(err, "invalid instance name nightly, store/nightly holds the RBI of the nightly channel")
+}
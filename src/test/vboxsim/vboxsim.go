@@ -0,0 +1,838 @@
+// Package vboxsim simulates VBoxManage in process so that the VirtualBox provider can be
+// tested without VirtualBox. VMs, host-only interfaces and media are kept in memory, only the
+// disks created by createmedium are written, empty, so that the provider finds them
+package vboxsim
+
+import (
+	"denver/pkg/util/executor"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultVersion is the version answered to `VBoxManage --version`
+const DefaultVersion = "6.1.38r153438"
+
+var nicFlagRegexp = regexp.MustCompile(`^--nic[0-9]+$`)
+
+const progress = "0%...10%...20%...30%...40%...50%...60%...70%...80%...90%...100%\n"
+
+// VM is a simulated virtual machine
+type VM struct {
+	Name            string
+	UUID            string
+	State           string
+	StateChangeTime time.Time
+	CPUs            int
+	Memory          int
+	// NICs are indexed from 1 like VirtualBox does, the value is the attachment type
+	NICs        map[int]string
+	HostOnlyIfs map[int]string
+	Forwards    []string
+	Controllers []string
+	// Attachments are indexed by "<controller>-<port>-<device>", the value is the medium UUID
+	Attachments map[string]string
+	Snapshots   []string
+}
+
+// Running tells whether the VM is running
+func (v *VM) Running() bool {
+	return v.State == "running"
+}
+
+// HostOnlyIf is a simulated host-only interface
+type HostOnlyIf struct {
+	Name string
+	IP   string
+	Mask string
+}
+
+// Medium is a simulated disk
+type Medium struct {
+	UUID string
+	Path string
+	// Size in megabytes
+	Size int
+}
+
+type failure struct {
+	prefix string
+	stderr string
+}
+
+// Simulator implements executor.Executor, each command changes the simulated host like VBoxManage
+type Simulator struct {
+	mutex       sync.Mutex
+	Version     string
+	vms         []*VM
+	hostOnlyIfs []*HostOnlyIf
+	media       []*Medium
+	failures    []failure
+	commands    [][]string
+	uuids       int
+	now         func() time.Time
+}
+
+// New returns a pointer to a Simulator without any VM
+func New() *Simulator {
+	return &Simulator{
+		Version: DefaultVersion,
+		now:     time.Now,
+	}
+}
+
+// FailOn makes the next command starting with prefix fail with stderr, nothing is changed
+func (s *Simulator) FailOn(stderr string, prefix ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures = append(s.failures, failure{prefix: executor.Quote(prefix), stderr: stderr})
+}
+
+// AddHostOnlyIf adds a host-only interface, as if it had been created before
+func (s *Simulator) AddHostOnlyIf(name, ip, mask string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.hostOnlyIfs = append(s.hostOnlyIfs, &HostOnlyIf{Name: name, IP: ip, Mask: mask})
+}
+
+// VM returns a copy of the VM named or identified by id, nil when it is not registered
+func (s *Simulator) VM(id string) *VM {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	vm := s.findVM(id)
+	if vm == nil {
+		return nil
+	}
+	copied := *vm
+	return &copied
+}
+
+// HostOnlyIfs returns the host-only interfaces, sorted by name
+func (s *Simulator) HostOnlyIfs() (ifs []HostOnlyIf) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, i := range s.sortedHostOnlyIfs() {
+		ifs = append(ifs, *i)
+	}
+	return
+}
+
+// Media returns the registered media
+func (s *Simulator) Media() (media []Medium) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, m := range s.media {
+		media = append(media, *m)
+	}
+	return
+}
+
+// Commands returns the commands run so far
+func (s *Simulator) Commands() [][]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.commands
+}
+
+// Execute runs a command against the simulated host
+func (s *Simulator) Execute(args []string) (string, error) {
+	return s.ExecuteWithOptions(args, executor.Options{})
+}
+
+// ExecuteWithOptions runs a command against the simulated host, the options are ignored
+func (s *Simulator) ExecuteWithOptions(args []string, options executor.Options) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.commands = append(s.commands, args)
+
+	command := executor.Quote(args)
+	for i, f := range s.failures {
+		if strings.HasPrefix(command, f.prefix) {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			return "", commandError(args, 1, f.stderr)
+		}
+	}
+
+	if len(args) < 2 || args[0] != "VBoxManage" {
+		return "", &executor.Error{Args: args, ExitCode: -1, Err: fmt.Errorf("exec: \"%s\": executable file not found in $PATH", args[0])}
+	}
+
+	handlers := map[string]func(args []string) (string, error){
+		"--version":      s.version,
+		"list":           s.list,
+		"showvminfo":     s.showVMInfo,
+		"createvm":       s.createVM,
+		"modifyvm":       s.modifyVM,
+		"startvm":        s.startVM,
+		"controlvm":      s.controlVM,
+		"unregistervm":   s.unregisterVM,
+		"storagectl":     s.storageCtl,
+		"storageattach":  s.storageAttach,
+		"createmedium":   s.createMedium,
+		"closemedium":    s.closeMedium,
+		"showmediuminfo": s.showMediumInfo,
+		"modifymedium":   s.modifyMedium,
+		"hostonlyif":     s.hostOnlyIf,
+		"snapshot":       s.snapshot,
+	}
+	handler, ok := handlers[args[1]]
+	if !ok {
+		return "", vboxError(args, "Invalid command '%s'", args[1])
+	}
+
+	return handler(args)
+}
+
+func (s *Simulator) version(args []string) (string, error) {
+	return s.Version + "\n", nil
+}
+
+func (s *Simulator) list(args []string) (string, error) {
+	if len(args) < 3 {
+		return "", vboxError(args, "Missing subcommand for \"list\"")
+	}
+
+	var out strings.Builder
+	switch args[2] {
+	case "vms", "runningvms":
+		for _, vm := range s.vms {
+			if args[2] == "vms" || vm.Running() {
+				fmt.Fprintf(&out, "\"%s\" {%s}\n", vm.Name, vm.UUID)
+			}
+		}
+	case "hostonlyifs":
+		for _, i := range s.sortedHostOnlyIfs() {
+			fmt.Fprintf(&out, "Name:            %s\n", i.Name)
+			fmt.Fprintf(&out, "GUID:            786f6276-656e-4074-8000-0a00270000%s\n", strings.TrimPrefix(i.Name, "vboxnet"))
+			fmt.Fprintf(&out, "DHCP:            Disabled\n")
+			fmt.Fprintf(&out, "IPAddress:       %s\n", i.IP)
+			fmt.Fprintf(&out, "NetworkMask:     %s\n", i.Mask)
+			fmt.Fprintf(&out, "IPV6Address:\n")
+			fmt.Fprintf(&out, "IPV6NetworkMaskPrefixLength: 0\n")
+			fmt.Fprintf(&out, "MediumType:      Ethernet\n")
+			fmt.Fprintf(&out, "Status:          Up\n")
+			fmt.Fprintf(&out, "VBoxNetworkName: HostInterfaceNetworking-%s\n\n", i.Name)
+		}
+	case "hdds":
+		for _, m := range s.media {
+			fmt.Fprintf(&out, "UUID:           %s\nLocation:       %s\nState:          created\n\n", m.UUID, m.Path)
+		}
+	default:
+		return "", vboxError(args, "Invalid parameter '%s'", args[2])
+	}
+
+	return out.String(), nil
+}
+
+func (s *Simulator) showVMInfo(args []string) (string, error) {
+	vm, err := s.argVM(args, len(args)-1)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "name=\"%s\"\n", vm.Name)
+	fmt.Fprintf(&out, "ostype=\"Ubuntu (64-bit)\"\n")
+	fmt.Fprintf(&out, "UUID=\"%s\"\n", vm.UUID)
+	fmt.Fprintf(&out, "memory=%d\n", vm.Memory)
+	fmt.Fprintf(&out, "cpus=%d\n", vm.CPUs)
+	fmt.Fprintf(&out, "VMState=\"%s\"\n", vm.State)
+	fmt.Fprintf(&out, "VMStateChangeTime=\"%s\"\n", vm.StateChangeTime.UTC().Format("2006-01-02T15:04:05.000000000"))
+	for i, controller := range vm.Controllers {
+		fmt.Fprintf(&out, "storagecontrollername%d=\"%s\"\n", i, controller)
+		for port := 0; port < 2; port++ {
+			key := fmt.Sprintf("%s-%d-0", controller, port)
+			uuid, ok := vm.Attachments[key]
+			if !ok {
+				fmt.Fprintf(&out, "\"%s\"=\"none\"\n", key)
+				continue
+			}
+			fmt.Fprintf(&out, "\"%s\"=\"%s\"\n", key, s.findMedium(uuid).Path)
+			fmt.Fprintf(&out, "\"%s\"=\"%s\"\n", fmt.Sprintf("%s-ImageUUID-%d-0", controller, port), uuid)
+		}
+	}
+	for index := 1; index <= 4; index++ {
+		nic, ok := vm.NICs[index]
+		if !ok {
+			nic = "none"
+		}
+		fmt.Fprintf(&out, "nic%d=\"%s\"\n", index, nic)
+		if nic == "hostonly" {
+			fmt.Fprintf(&out, "hostonlyadapter%d=\"%s\"\n", index, vm.HostOnlyIfs[index])
+		}
+		if nic == "nat" {
+			for i, forward := range vm.Forwards {
+				fmt.Fprintf(&out, "Forwarding(%d)=\"%s\"\n", i, forward)
+			}
+		}
+	}
+	for i, snapshot := range vm.Snapshots {
+		suffix := ""
+		if i > 0 {
+			suffix = fmt.Sprintf("-%d", i)
+		}
+		fmt.Fprintf(&out, "SnapshotName%s=\"%s\"\n", suffix, snapshot)
+	}
+	if len(vm.Snapshots) > 0 {
+		fmt.Fprintf(&out, "CurrentSnapshotName=\"%s\"\n", vm.Snapshots[len(vm.Snapshots)-1])
+		fmt.Fprintf(&out, "CurrentSnapshotUUID=\"%s\"\n", s.snapshotUUID(vm, len(vm.Snapshots)-1))
+	}
+
+	return out.String(), nil
+}
+
+func (s *Simulator) createVM(args []string) (string, error) {
+	flags, _ := parseFlags(args[2:])
+	name := flags["--name"]
+	if name == "" {
+		return "", vboxError(args, "Parameter --name is required")
+	}
+	if s.findVM(name) != nil {
+		return "", vboxError(args, "Machine settings file '/home/jdoe/VirtualBox VMs/%s/%s.vbox' already exists", name, name)
+	}
+
+	vm := &VM{
+		Name:            name,
+		UUID:            s.newUUID(),
+		State:           "poweroff",
+		StateChangeTime: s.now(),
+		CPUs:            1,
+		Memory:          128,
+		NICs:            map[int]string{1: "nat"},
+		HostOnlyIfs:     map[int]string{},
+		Attachments:     map[string]string{},
+	}
+	s.vms = append(s.vms, vm)
+
+	return fmt.Sprintf("Virtual machine '%s' is created and registered.\nUUID: %s\nSettings file: '/home/jdoe/VirtualBox VMs/%s/%s.vbox'\n", name, vm.UUID, name, name), nil
+}
+
+func (s *Simulator) modifyVM(args []string) (string, error) {
+	vm, err := s.argVM(args, 2)
+	if err != nil {
+		return "", err
+	}
+	if vm.Running() {
+		return "", lockedError(args, vm)
+	}
+
+	flags, natpf := parseFlags(args[3:])
+	for name, value := range flags {
+		switch {
+		case name == "--cpus":
+			vm.CPUs, _ = strconv.Atoi(value)
+		case name == "--memory":
+			vm.Memory, _ = strconv.Atoi(value)
+		case nicFlagRegexp.MatchString(name):
+			index, _ := strconv.Atoi(strings.TrimPrefix(name, "--nic"))
+			vm.NICs[index] = value
+		case strings.HasPrefix(name, "--hostonlyadapter"):
+			index, _ := strconv.Atoi(strings.TrimPrefix(name, "--hostonlyadapter"))
+			if s.findHostOnlyIf(value) == nil {
+				return "", vboxError(args, "Host-only interface '%s' not found", value)
+			}
+			vm.HostOnlyIfs[index] = value
+		}
+	}
+
+	return "", s.applyNatpf(args, vm, natpf)
+}
+
+func (s *Simulator) startVM(args []string) (string, error) {
+	vm, err := s.argVM(args, 2)
+	if err != nil {
+		return "", err
+	}
+	if vm.Running() {
+		return "", lockedError(args, vm)
+	}
+
+	s.setState(vm, "running")
+	return fmt.Sprintf("Waiting for VM \"%s\" to power on...\nVM \"%s\" has been successfully started.\n", vm.Name, vm.Name), nil
+}
+
+func (s *Simulator) controlVM(args []string) (string, error) {
+	vm, err := s.argVM(args, 2)
+	if err != nil {
+		return "", err
+	}
+	if len(args) < 4 {
+		return "", vboxError(args, "Not enough parameters")
+	}
+	if !vm.Running() {
+		return "", vboxError(args, "Machine '%s' is not currently running", vm.Name)
+	}
+
+	switch args[3] {
+	case "acpipowerbutton", "poweroff":
+		// The guest is assumed to shut down right away
+		s.setState(vm, "poweroff")
+		if args[3] == "poweroff" {
+			return progress, nil
+		}
+	case "savestate":
+		s.setState(vm, "saved")
+		return progress, nil
+	case "natpf1":
+		return "", s.applyNatpf(args, vm, [][]string{args[4:]})
+	default:
+		return "", vboxError(args, "Invalid parameter '%s'", args[3])
+	}
+
+	return "", nil
+}
+
+func (s *Simulator) unregisterVM(args []string) (string, error) {
+	vm, err := s.argVM(args, 2)
+	if err != nil {
+		return "", err
+	}
+	if vm.Running() {
+		return "", vboxError(args, "Cannot unregister the machine '%s' while it is locked", vm.Name)
+	}
+
+	// --delete removes the disks still attached with the VM
+	if len(args) > 3 && args[3] == "--delete" {
+		for _, uuid := range vm.Attachments {
+			medium := s.findMedium(uuid)
+			_ = os.Remove(medium.Path)
+			s.removeMedium(medium)
+		}
+	}
+
+	for i, v := range s.vms {
+		if v == vm {
+			s.vms = append(s.vms[:i], s.vms[i+1:]...)
+			break
+		}
+	}
+
+	if len(args) > 3 && args[3] == "--delete" {
+		return progress, nil
+	}
+	return "", nil
+}
+
+func (s *Simulator) storageCtl(args []string) (string, error) {
+	vm, err := s.argVM(args, 2)
+	if err != nil {
+		return "", err
+	}
+	if vm.Running() {
+		return "", lockedError(args, vm)
+	}
+
+	flags, _ := parseFlags(args[3:])
+	for _, controller := range vm.Controllers {
+		if controller == flags["--name"] {
+			return "", vboxError(args, "Storage controller named '%s' already exists", controller)
+		}
+	}
+	vm.Controllers = append(vm.Controllers, flags["--name"])
+
+	return "", nil
+}
+
+func (s *Simulator) storageAttach(args []string) (string, error) {
+	vm, err := s.argVM(args, 2)
+	if err != nil {
+		return "", err
+	}
+	if vm.Running() {
+		return "", lockedError(args, vm)
+	}
+
+	flags, _ := parseFlags(args[3:])
+	controller := flags["--storagectl"]
+	found := false
+	for _, c := range vm.Controllers {
+		found = found || c == controller
+	}
+	if !found {
+		return "", vboxError(args, "Could not find a controller named '%s'", controller)
+	}
+
+	key := fmt.Sprintf("%s-%s-0", controller, flags["--port"])
+	if flags["--medium"] == "none" {
+		if _, ok := vm.Attachments[key]; !ok {
+			return "", vboxError(args, "No storage device attached to device slot 0 on port %s of controller '%s'", flags["--port"], controller)
+		}
+		delete(vm.Attachments, key)
+		return "", nil
+	}
+
+	// An unknown disk is registered when it is attached, like VBoxManage does
+	medium, err := s.openMedium(args, flags["--medium"])
+	if err != nil {
+		return "", err
+	}
+	for _, v := range s.vms {
+		for _, uuid := range v.Attachments {
+			if uuid == medium.UUID {
+				return "", vboxError(args, "Medium '%s' is already attached to the virtual machine '%s'", medium.Path, v.Name)
+			}
+		}
+	}
+	vm.Attachments[key] = medium.UUID
+
+	return "", nil
+}
+
+func (s *Simulator) createMedium(args []string) (string, error) {
+	flags, _ := parseFlags(args[3:])
+	path := flags["--filename"]
+	if s.findMedium(path) != nil {
+		return "", vboxError(args, "Could not create the medium storage unit '%s'.\nVBoxManage: error: VDI: cannot create image '%s' (VERR_ALREADY_EXISTS)", path, path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", vboxError(args, "Could not create the medium storage unit '%s'.\nVBoxManage: error: VDI: cannot create image '%s' (VERR_ALREADY_EXISTS)", path, path)
+	}
+	if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+		return "", vboxError(args, "Could not create the medium storage unit '%s'.\nVBoxManage: error: %s", path, err)
+	}
+
+	medium := &Medium{UUID: s.newUUID(), Path: path}
+	medium.Size, _ = strconv.Atoi(flags["--size"])
+	s.media = append(s.media, medium)
+
+	return fmt.Sprintf("%sMedium created. UUID: %s\n", progress, medium.UUID), nil
+}
+
+func (s *Simulator) closeMedium(args []string) (string, error) {
+	if len(args) < 4 {
+		return "", vboxError(args, "Not enough parameters")
+	}
+	medium := s.findMedium(args[3])
+	if medium == nil {
+		return "", vboxError(args, "Could not find file for the medium '%s' (VERR_FILE_NOT_FOUND)", args[3])
+	}
+
+	for _, vm := range s.vms {
+		for _, uuid := range vm.Attachments {
+			if uuid == medium.UUID {
+				return "", vboxError(args, "Cannot close medium '%s' because it is still attached to 1 virtual machines", medium.Path)
+			}
+		}
+	}
+
+	s.removeMedium(medium)
+	if len(args) > 4 && args[4] == "--delete" {
+		_ = os.Remove(medium.Path)
+		return progress, nil
+	}
+
+	return "", nil
+}
+
+func (s *Simulator) showMediumInfo(args []string) (string, error) {
+	if len(args) < 4 {
+		return "", vboxError(args, "Not enough parameters")
+	}
+	medium, err := s.openMedium(args, args[3])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("UUID:           %s\nParent UUID:    base\nState:          created\nType:           normal (base)\nLocation:       %s\nStorage format: VDI\nFormat variant: dynamic default\nCapacity:       %d MBytes\nSize on disk:   2 MBytes\nEncryption:     disabled\n",
+		medium.UUID, medium.Path, medium.Size), nil
+}
+
+func (s *Simulator) modifyMedium(args []string) (string, error) {
+	if len(args) < 4 {
+		return "", vboxError(args, "Not enough parameters")
+	}
+	medium, err := s.openMedium(args, args[3])
+	if err != nil {
+		return "", err
+	}
+
+	flags, _ := parseFlags(args[4:])
+	if resize, ok := flags["--resize"]; ok {
+		size, _ := strconv.Atoi(resize)
+		if size < medium.Size {
+			return "", vboxError(args, "Failed to resize medium\nVBoxManage: error: Shrinking is not yet supported for medium '%s'", medium.Path)
+		}
+		medium.Size = size
+		return progress, nil
+	}
+
+	return "", nil
+}
+
+func (s *Simulator) hostOnlyIf(args []string) (string, error) {
+	if len(args) < 3 {
+		return "", vboxError(args, "Not enough parameters")
+	}
+
+	switch args[2] {
+	case "create":
+		index := 0
+		for s.findHostOnlyIf(fmt.Sprintf("vboxnet%d", index)) != nil {
+			index++
+		}
+		i := &HostOnlyIf{
+			Name: fmt.Sprintf("vboxnet%d", index),
+			IP:   fmt.Sprintf("192.168.%d.1", 56+index),
+			Mask: "255.255.255.0",
+		}
+		s.hostOnlyIfs = append(s.hostOnlyIfs, i)
+		return fmt.Sprintf("%sInterface '%s' was successfully created\n", progress, i.Name), nil
+	case "ipconfig", "remove":
+		if len(args) < 4 {
+			return "", vboxError(args, "Not enough parameters")
+		}
+		i := s.findHostOnlyIf(args[3])
+		if i == nil {
+			return "", vboxError(args, "could not find interface '%s'", args[3])
+		}
+		if args[2] == "remove" {
+			for index, h := range s.hostOnlyIfs {
+				if h == i {
+					s.hostOnlyIfs = append(s.hostOnlyIfs[:index], s.hostOnlyIfs[index+1:]...)
+					break
+				}
+			}
+			return progress, nil
+		}
+		flags, _ := parseFlags(args[4:])
+		i.IP, i.Mask = flags["--ip"], flags["--netmask"]
+		return "", nil
+	}
+
+	return "", vboxError(args, "Invalid parameter '%s'", args[2])
+}
+
+func (s *Simulator) snapshot(args []string) (string, error) {
+	vm, err := s.argVM(args, 2)
+	if err != nil {
+		return "", err
+	}
+	if len(args) < 4 {
+		return "", vboxError(args, "Not enough parameters")
+	}
+
+	if args[3] == "list" {
+		if len(vm.Snapshots) == 0 {
+			return "", vboxError(args, "This machine does not have any snapshots")
+		}
+		var out strings.Builder
+		for i, name := range vm.Snapshots {
+			suffix := ""
+			if i > 0 {
+				suffix = fmt.Sprintf("-%d", i)
+			}
+			fmt.Fprintf(&out, "SnapshotName%s=\"%s\"\nSnapshotUUID%s=\"%s\"\n", suffix, name, suffix, s.snapshotUUID(vm, i))
+		}
+		return out.String(), nil
+	}
+
+	if len(args) < 5 {
+		return "", vboxError(args, "Not enough parameters")
+	}
+	name := args[4]
+	index := -1
+	for i, snapshot := range vm.Snapshots {
+		if snapshot == name {
+			index = i
+		}
+	}
+
+	switch args[3] {
+	case "take":
+		vm.Snapshots = append(vm.Snapshots, name)
+		return fmt.Sprintf("%sSnapshot taken. UUID: %s\n", progress, s.snapshotUUID(vm, len(vm.Snapshots)-1)), nil
+	case "restore", "delete":
+		if index < 0 {
+			return "", vboxError(args, "Could not find a snapshot named '%s'", name)
+		}
+		if args[3] == "restore" && vm.Running() {
+			return "", lockedError(args, vm)
+		}
+		if args[3] == "delete" {
+			vm.Snapshots = append(vm.Snapshots[:index], vm.Snapshots[index+1:]...)
+		}
+		return progress, nil
+	}
+
+	return "", vboxError(args, "Invalid parameter '%s'", args[3])
+}
+
+// applyNatpf handles `--natpf1 <rule>` and `--natpf1 delete <name>`
+func (s *Simulator) applyNatpf(args []string, vm *VM, natpf [][]string) error {
+	for _, values := range natpf {
+		if len(values) == 0 {
+			return vboxError(args, "Not enough parameters")
+		}
+
+		if values[0] == "delete" {
+			if len(values) < 2 {
+				return vboxError(args, "Not enough parameters")
+			}
+			found := false
+			for i, forward := range vm.Forwards {
+				if strings.HasPrefix(forward, values[1]+",") {
+					vm.Forwards = append(vm.Forwards[:i], vm.Forwards[i+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return vboxError(args, "Code NS_ERROR_INVALID_ARG (0x80070057) - Invalid argument value")
+			}
+			continue
+		}
+
+		name := strings.Split(values[0], ",")[0]
+		for _, forward := range vm.Forwards {
+			if strings.HasPrefix(forward, name+",") {
+				return vboxError(args, "A NAT rule of this name already exists")
+			}
+		}
+		vm.Forwards = append(vm.Forwards, values[0])
+	}
+
+	return nil
+}
+
+func (s *Simulator) openMedium(args []string, path string) (*Medium, error) {
+	if medium := s.findMedium(path); medium != nil {
+		return medium, nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, vboxError(args, "Could not find file for the medium '%s' (VERR_FILE_NOT_FOUND)", path)
+	}
+
+	medium := &Medium{UUID: s.newUUID(), Path: path}
+	s.media = append(s.media, medium)
+	return medium, nil
+}
+
+func (s *Simulator) argVM(args []string, index int) (*VM, error) {
+	if len(args) <= index {
+		return nil, vboxError(args, "Not enough parameters")
+	}
+
+	vm := s.findVM(args[index])
+	if vm == nil {
+		return nil, vboxError(args, "Could not find a registered machine named '%s'", args[index])
+	}
+	return vm, nil
+}
+
+func (s *Simulator) findVM(id string) *VM {
+	for _, vm := range s.vms {
+		if vm.Name == id || vm.UUID == id {
+			return vm
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) findMedium(id string) *Medium {
+	for _, m := range s.media {
+		if m.UUID == id || m.Path == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) removeMedium(medium *Medium) {
+	for i, m := range s.media {
+		if m == medium {
+			s.media = append(s.media[:i], s.media[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Simulator) findHostOnlyIf(name string) *HostOnlyIf {
+	for _, i := range s.hostOnlyIfs {
+		if i.Name == name {
+			return i
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) sortedHostOnlyIfs() []*HostOnlyIf {
+	ifs := append([]*HostOnlyIf{}, s.hostOnlyIfs...)
+	sort.Slice(ifs, func(i, j int) bool {
+		return ifs[i].Name < ifs[j].Name
+	})
+	return ifs
+}
+
+func (s *Simulator) setState(vm *VM, state string) {
+	vm.State = state
+	vm.StateChangeTime = s.now()
+}
+
+func (s *Simulator) newUUID() string {
+	s.uuids++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.uuids)
+}
+
+func (s *Simulator) snapshotUUID(vm *VM, index int) string {
+	return fmt.Sprintf("%s-snapshot-%d", vm.UUID, index)
+}
+
+// parseFlags reads `--name value` pairs, the `--natpf1` values are returned apart as a rule
+// may be `delete <name>` and the flag be given several times
+func parseFlags(args []string) (flags map[string]string, natpf [][]string) {
+	flags = map[string]string{}
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "--") {
+			continue
+		}
+		if args[i] == "--natpf1" && i+1 < len(args) {
+			if args[i+1] == "delete" && i+2 < len(args) {
+				natpf = append(natpf, args[i+1:i+3])
+				i += 2
+				continue
+			}
+			natpf = append(natpf, args[i+1:i+2])
+			i++
+			continue
+		}
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			flags[args[i]] = args[i+1]
+			i++
+			continue
+		}
+		flags[args[i]] = ""
+	}
+	return
+}
+
+func vboxError(args []string, format string, a ...interface{}) error {
+	return commandError(args, 1, fmt.Sprintf("VBoxManage: error: "+format+"\n", a...))
+}
+
+func lockedError(args []string, vm *VM) error {
+	return vboxError(args, "The machine '%s' is already locked for a session (or being unlocked)", vm.Name)
+}
+
+func commandError(args []string, exitCode int, stderr string) error {
+	return &executor.Error{
+		Args:     args,
+		ExitCode: exitCode,
+		Stderr:   stderr,
+		Err:      errors.New("exit status " + strconv.Itoa(exitCode)),
+	}
+}